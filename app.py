@@ -175,7 +175,10 @@ class GeneticNQueensApp(tk.Tk):
             return False
 
         try:
-            subprocess.run([executable, *args], check=True)
+            subprocess.run(
+                [executable, "run", *args, "-output", "results.json", "-overwrite"],
+                check=True,
+            )
         except subprocess.CalledProcessError:
             messagebox.showerror(
                 "Error",
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+// Run every combination of the given configuration values and print a table comparing them
+func benchCommand(args []string) {
+	fs := newFlagSet("bench", "[flags]", "Run every combination of the comma-separated values of the sweep flags (e.g. -numQueens 8,16,32 -crossover ox,pmx)\nand print the results of every combination. Every combination uses the same seed.")
+	var configPath string
+	var numQueensList string
+	var populationSizeList string
+	var selectionMethodList string
+	var crossoverList string
+	var mutationList string
+	var mutationRateList string
+	var crossOverRateList string
+	fs.StringVar(&configPath, "config", "", "Path to a JSON, YAML or TOML configuration file with the values that aren't swept. Omitted fields keep their default values.")
+	fs.Int("numRuns", config.DefaultConfig.NumRuns, "Number of runs of every combination.")
+	fs.Int("maxGenerations", config.DefaultConfig.MaxGenerations, "Maximum number of generations of every run.")
+	fs.Uint64("seed", config.DefaultConfig.Seed, "Seed shared by every combination. 0 picks a random seed.")
+	fs.Int("workers", config.DefaultConfig.Workers, "Number of goroutines evaluating and breeding the population of every run.")
+	fs.Duration("maxDuration", time.Duration(config.DefaultConfig.MaxDuration), "Maximum duration of every run (e.g. 1m30s). 0 means no limit.")
+	fs.StringVar(&numQueensList, "numQueens", "", "Numbers of queens to sweep.")
+	fs.StringVar(&populationSizeList, "populationSize", "", "Population sizes to sweep.")
+	fs.StringVar(&selectionMethodList, "selectionMethod", "", fmt.Sprintf("Selection methods to sweep. Available: %s.", strings.Join(operator.Selectors(), ", ")))
+	fs.StringVar(&crossoverList, "crossover", "", fmt.Sprintf("Crossover operators to sweep. Available: %s.", strings.Join(operator.Crossoverers(), ", ")))
+	fs.StringVar(&mutationList, "mutation", "", fmt.Sprintf("Mutation operators to sweep. Available: %s.", strings.Join(operator.Mutators(), ", ")))
+	fs.StringVar(&mutationRateList, "mutationRate", "", "Mutation rates to sweep.")
+	fs.StringVar(&crossOverRateList, "crossOverRate", "", "Crossover rates to sweep.")
+	fs.Parse(args)
+
+	// Swept flags share their names with the ones of the run command, but only these set the base configuration
+	layered, err := loadLayered(fs, map[string]string{
+		"numRuns":        "num_runs",
+		"maxGenerations": "max_generations",
+		"seed":           "seed",
+		"workers":        "workers",
+		"maxDuration":    "max_duration",
+	}, configPath, slog.Default())
+	if err != nil {
+		log.Fatal(err)
+	}
+	base := layered.Config
+	if base.Seed == 0 {
+		base.Seed = rand.Uint64()
+	}
+
+	cfgs := []config.Config{base}
+	cfgs, err = sweep(cfgs, numQueensList, strconv.Atoi, func(c *config.Config, v int) { c.NumQueens = v })
+	if err == nil {
+		cfgs, err = sweep(cfgs, populationSizeList, strconv.Atoi, func(c *config.Config, v int) { c.PopulationSize = v })
+	}
+	if err == nil {
+		cfgs, err = sweep(cfgs, selectionMethodList, parseString, func(c *config.Config, v string) { c.SelectionMethod = config.SelectionMethodType(v) })
+	}
+	if err == nil {
+		cfgs, err = sweep(cfgs, crossoverList, parseString, func(c *config.Config, v string) { c.Crossover = v })
+	}
+	if err == nil {
+		cfgs, err = sweep(cfgs, mutationList, parseString, func(c *config.Config, v string) { c.Mutation = v })
+	}
+	if err == nil {
+		cfgs, err = sweep(cfgs, mutationRateList, parseFloat, func(c *config.Config, v float64) { c.MutationRate = v })
+	}
+	if err == nil {
+		cfgs, err = sweep(cfgs, crossOverRateList, parseFloat, func(c *config.Config, v float64) { c.CrossOverRate = v })
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Check every combination before running any of them
+	solvers := make([]*nqueens.Solver, len(cfgs))
+	for i, cfg := range cfgs {
+		if solvers[i], err = nqueens.New(cfg); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println("Running", len(cfgs), "combinations of", base.NumRuns, "runs with seed", base.Seed)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "QUEENS\tPOPULATION\tSELECTION\tCROSSOVER\tMUTATION\tMUTATION RATE\tCROSSOVER RATE\tSOLUTIONS\tMEAN GENERATIONS\tMEAN BEST FITNESS\tTIME")
+	for i, solver := range solvers {
+		if ctx.Err() != nil {
+			break
+		}
+
+		fmt.Fprintf(os.Stderr, "Running combination %v of %v\n", i+1, len(cfgs))
+		res, err := solver.Solve(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Fatal(err)
+		}
+		cfg := res.Config
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v/%v\t%.1f\t%.2f\t%v\n",
+			cfg.NumQueens, cfg.PopulationSize, cfg.SelectionMethod, cfg.Crossover, cfg.Mutation, cfg.MutationRate, cfg.CrossOverRate,
+			res.NumSolutions(), len(res.Runs), res.MeanGenerations(), res.MeanBestFitness(), res.Elapsed().Round(time.Millisecond))
+	}
+	w.Flush()
+}
+
+// Replace every configuration with a copy for every value of a comma-separated list
+// The configurations are returned unchanged if the list is empty
+func sweep[T any](cfgs []config.Config, list string, parse func(string) (T, error), set func(*config.Config, T)) ([]config.Config, error) {
+	if list == "" {
+		return cfgs, nil
+	}
+
+	values := []T{}
+	for _, s := range strings.Split(list, ",") {
+		v, err := parse(strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("invalid sweep value %q: %w", s, err)
+		}
+		values = append(values, v)
+	}
+
+	swept := make([]config.Config, 0, len(cfgs)*len(values))
+	for _, cfg := range cfgs {
+		for _, v := range values {
+			set(&cfg, v)
+			swept = append(swept, cfg)
+		}
+	}
+	return swept, nil
+}
+
+func parseString(s string) (string, error) {
+	return s, nil
+}
+
+func parseFloat(s string) (float64, error) {
+	return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Read a board from command line arguments written as the row of the queen of every column
+// Rows can be separated by commas or spaces and surrounded by brackets, e.g. "1,3,0,2", "1 3 0 2" or "[1, 3, 0, 2]"
+func parseBoard(args []string) ([]int, error) {
+	fields := strings.FieldsFunc(strings.Join(args, " "), func(r rune) bool {
+		return r == ',' || r == '[' || r == ']' || unicode.IsSpace(r)
+	})
+	if len(fields) == 0 {
+		return nil, errors.New("no board given")
+	}
+
+	board := make([]int, len(fields))
+	for col, field := range fields {
+		row, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid row %q in column %v", field, col)
+		}
+		board[col] = row
+	}
+	if err := checkBoard(board); err != nil {
+		return nil, err
+	}
+	return board, nil
+}
+
+// Check that a board has queens and that every queen is inside of it
+func checkBoard(board []int) error {
+	if len(board) == 0 {
+		return errors.New("no queens on the board")
+	}
+	for col, row := range board {
+		if row < 0 || row >= len(board) {
+			return fmt.Errorf("row %v in column %v is outside of the board", row, col)
+		}
+	}
+	return nil
+}
+
+// Get the board of the given run of a results file, or the best one if the run ID is 0
+func boardFromResults(path string, runID int) ([]int, error) {
+	results, err := result.LoadResultsFromFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, fmt.Errorf("%v has no results", path)
+	}
+
+	best := results[0]
+	for _, r := range results {
+		if runID == 0 && r.BestFitness > best.BestFitness {
+			best = r
+		}
+		if runID != 0 && r.RunID == runID {
+			return r.BestQueenPositions, nil
+		}
+	}
+	if runID != 0 {
+		return nil, fmt.Errorf("%v has no run %v", path, runID)
+	}
+	return best.BestQueenPositions, nil
+}
+
+// Represents the clashes between the queens of a board
+// Conflicted: Whether the queen of every column is attacked by another queen
+type clashReport struct {
+	RowClashes      int
+	DiagonalClashes int
+	Conflicted      []bool
+}
+
+// Count the pairs of queens attacking each other
+// Boards don't need to be permutations, so queens sharing a row are counted as well
+func findClashes(board []int) clashReport {
+	ind := &individual.Individual{QueenPositions: board}
+	numQueens := len(board)
+	report := clashReport{
+		DiagonalClashes: numQueens*(numQueens-1)/2 - ind.GenotypeFitness(),
+		Conflicted:      make([]bool, numQueens),
+	}
+
+	queensPerRow := make([]int, numQueens)
+	for _, row := range board {
+		report.RowClashes += queensPerRow[row]
+		queensPerRow[row]++
+	}
+	for col, row := range board {
+		report.Conflicted[col] = queensPerRow[row] > 1 || ind.IsInConflict(col)
+	}
+	return report
+}
+
+// Get the total number of pairs of queens attacking each other
+func (r clashReport) Total() int {
+	return r.RowClashes + r.DiagonalClashes
+}
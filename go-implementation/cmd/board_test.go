@@ -0,0 +1,61 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseBoard(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		want    []int
+		wantErr bool
+	}{
+		{"Commas", []string{"1,3,0,2"}, []int{1, 3, 0, 2}, false},
+		{"Spaces", []string{"1", "3", "0", "2"}, []int{1, 3, 0, 2}, false},
+		{"Brackets", []string{"[1, 3, 0, 2]"}, []int{1, 3, 0, 2}, false},
+		{"Empty", []string{}, nil, true},
+		{"Not a number", []string{"1,a,0,2"}, nil, true},
+		{"Outside of the board", []string{"1,4,0,2"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseBoard(tt.args)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseBoard() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseBoard() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_findClashes(t *testing.T) {
+	tests := []struct {
+		name  string
+		board []int
+		want  clashReport
+	}{
+		{"Solution", []int{1, 3, 0, 2}, clashReport{Conflicted: []bool{false, false, false, false}}},
+		{"Diagonal", []int{0, 1, 3, 2}, clashReport{DiagonalClashes: 2, Conflicted: []bool{true, true, true, true}}},
+		{"Same row", []int{1, 1, 3, 0}, clashReport{RowClashes: 1, DiagonalClashes: 1, Conflicted: []bool{true, true, true, false}}},
+		{"Some attacked", []int{3, 1, 0, 2}, clashReport{DiagonalClashes: 1, Conflicted: []bool{false, true, true, false}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findClashes(tt.board); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("findClashes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_renderText(t *testing.T) {
+	want := ". . Q .\nQ . . .\n. . . Q\n. Q . .\n"
+	if got := renderText([]int{1, 3, 0, 2}); got != want {
+		t.Errorf("renderText() = %q, want %q", got, want)
+	}
+}
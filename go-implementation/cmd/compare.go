@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/internal/stats"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+)
+
+// Compare the runs of two results files with descriptive statistics, confidence intervals and significance tests
+func compareCommand(args []string) {
+	fs := newFlagSet("compare", "[flags] <a.json> <b.json>", "Compare the best generation of every run of two results files: success rate with Wilson intervals and Fisher's exact test,\nand the distribution of every metric with bootstrap intervals and the Mann-Whitney U test.")
+	var level float64
+	var resamples int
+	var seed uint64
+	fs.Float64Var(&level, "level", 0.95, "Confidence level of the intervals, between 0 and 1.")
+	fs.IntVar(&resamples, "resamples", 10000, "Number of resamples of the bootstrap intervals.")
+	fs.Uint64Var(&seed, "seed", 1, "Seed of the bootstrap resamples, so the same files always give the same intervals.")
+	fs.Parse(args)
+
+	if fs.NArg() != 2 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	if level <= 0 || level >= 1 {
+		log.Fatalf("confidence level must be between 0 and 1, got %v", level)
+	}
+	if resamples < 1 {
+		log.Fatalf("number of resamples must be at least 1, got %v", resamples)
+	}
+
+	a, err := result.LoadResultsFromFile(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	b, err := result.LoadResultsFromFile(fs.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	c := stats.Compare(util.NewRNG(seed, 0), a, b, level, resamples)
+	printComparison(os.Stdout, fs.Arg(0), fs.Arg(1), c)
+}
+
+// Print the comparison of two results files, called A and B in the tables
+func printComparison(out io.Writer, pathA, pathB string, c stats.Comparison) {
+	fmt.Fprintf(out, "A: %v (%v runs)\nB: %v (%v runs)\n\n", pathA, c.Success.ARuns, pathB, c.Success.BRuns)
+
+	s := c.Success
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "\tSOLUTIONS\tSUCCESS RATE\t%v%% WILSON INTERVAL\n", c.Level*100)
+	fmt.Fprintf(w, "A\t%v/%v\t%.1f%%\t[%.1f%%, %.1f%%]\n", s.ASolutions, s.ARuns, s.ARate*100, s.ACI.Lower*100, s.ACI.Upper*100)
+	fmt.Fprintf(w, "B\t%v/%v\t%.1f%%\t[%.1f%%, %.1f%%]\n", s.BSolutions, s.BRuns, s.BRate*100, s.BCI.Lower*100, s.BCI.Upper*100)
+	w.Flush()
+	fmt.Fprintf(out, "Fisher's exact test: p = %.4g\n\n", s.FisherP)
+
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "METRIC\t\tMEAN\tSTD DEV\tMIN\tQ1\tMEDIAN\tQ3\tMAX\t%v%% MEAN INTERVAL\t%v%% MEDIAN INTERVAL\n", c.Level*100, c.Level*100)
+	for _, m := range c.Metrics {
+		printSummary(w, m.Metric, "A", m.A, m.AMeanCI, m.AMedianCI)
+		printSummary(w, "", "B", m.B, m.BMeanCI, m.BMedianCI)
+	}
+	w.Flush()
+	fmt.Fprintln(out)
+
+	w = tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "MANN-WHITNEY U TEST\tU\tZ\tP")
+	for _, m := range c.Metrics {
+		fmt.Fprintf(w, "%v\t%.1f\t%.3f\t%.4g\n", m.Metric, m.MannWhitney.U, m.MannWhitney.Z, m.MannWhitney.P)
+	}
+	w.Flush()
+}
+
+func printSummary(w io.Writer, metric, name string, s stats.Summary, meanCI, medianCI stats.Interval) {
+	fmt.Fprintf(w, "%v\t%v\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t%.3f\t[%.3f, %.3f]\t[%.3f, %.3f]\n",
+		metric, name, s.Mean, s.StdDev, s.Min, s.Q1, s.Median, s.Q3, s.Max, meanCI.Lower, meanCI.Upper, medianCI.Lower, medianCI.Upper)
+}
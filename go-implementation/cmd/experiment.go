@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/experiment"
+)
+
+// Run every cell of an experiment file and write a report aggregating the runs of every cell
+func experimentCommand(args []string) {
+	fs := newFlagSet("experiment", "[flags] <experiment.yaml>", "Run every combination of the grid of an experiment file, or a random sample of them, NumRuns times each\nand write a report aggregating the runs of every combination. The file can be JSON, YAML or TOML.")
+	var outputPath string
+	var overwrite bool
+	var parallel int
+	fs.StringVar(&outputPath, "output", "experiment.json", "Path of the JSON report.")
+	fs.BoolVar(&overwrite, "overwrite", false, "Replace the report if it exists instead of adding a numeric suffix to its name.")
+	fs.IntVar(&parallel, "parallel", 0, "Number of runs executed at the same time. 0 uses the value of the experiment file, or one per CPU.")
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	exp, err := experiment.Load(fs.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if exp.Seed == 0 {
+		exp.Seed = rand.Uint64()
+	}
+	if parallel > 0 {
+		exp.Parallel = parallel
+	}
+	if exp.Parallel < 1 {
+		exp.Parallel = runtime.NumCPU()
+	}
+
+	cells, err := exp.Cells()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Fprintln(os.Stderr, "Running", len(cells), "cells with seed", exp.Seed, "and", exp.Parallel, "runs at the same time")
+	report := experiment.Report{Name: exp.Name, Seed: exp.Seed, StartedAt: time.Now()}
+	numDone := 0
+	cellResults, runErr := experiment.Run(ctx, cells, exp.Parallel, func(r experiment.CellResult) {
+		numDone++
+		fmt.Fprintf(os.Stderr, "Finished cell %v (%v) in %v, %v of %v cells done\n", r.Cell.ID, r.Cell.Label(), r.Duration.Round(time.Millisecond), numDone, len(cells))
+	})
+	// Every cell is checked before running any of them, so there are no results if a cell is invalid
+	if cellResults == nil {
+		log.Fatal(runErr)
+	}
+	report.FinishedAt = time.Now()
+	for _, r := range cellResults {
+		report.Cells = append(report.Cells, experiment.NewCellReport(r))
+	}
+
+	printReport(report)
+	path, err := saveFile(outputPath, overwrite, report.WriteJSON)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("Report saved to", path)
+
+	// Runs stopped by an error are in the report, but the experiment has still failed
+	if runErr != nil {
+		log.Fatal(runErr)
+	}
+}
+
+// Print a table with the parameters and the aggregated results of every cell
+func printReport(report experiment.Report) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CELL\tPARAMETERS\tSOLUTIONS\tMEAN GENERATIONS\tMEAN BEST FITNESS\tBEST FITNESS\tTIME")
+	for _, c := range report.Cells {
+		fmt.Fprintf(w, "%v\t%v\t%v/%v\t%.1f\t%.2f\t%v\t%v\n",
+			c.Cell.ID, c.Cell.Label(), c.NumSolutions, c.NumRuns, c.MeanGenerations, c.MeanBestFitness, c.BestFitness, c.Duration.Round(time.Millisecond))
+	}
+	w.Flush()
+}
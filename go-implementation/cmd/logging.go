@@ -0,0 +1,132 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+// Formats of the logs
+var logFormats = []string{"text", "json"}
+
+// Number of milestones logged during a run that reaches the maximum number of generations
+const numMilestones = 10
+
+// Represents the flags choosing how the logs are written
+type logFlags struct {
+	format string
+	level  string
+	quiet  bool
+}
+
+// Define the flags choosing how the logs are written
+func defineLogFlags(fs *flag.FlagSet) *logFlags {
+	f := &logFlags{}
+	fs.StringVar(&f.format, "log-format", "text", fmt.Sprintf("Format of the logs written to standard error. Available: %s.", strings.Join(logFormats, ", ")))
+	fs.StringVar(&f.level, "log-level", "info", "Minimum level of the logs: debug, info, warn or error. Debug also logs the progress of every run every tenth of the generations.")
+	fs.BoolVar(&f.quiet, "quiet", false, "Only log warnings and errors, regardless of -log-level.")
+	return f
+}
+
+// Get the minimum level of the logs
+func (f *logFlags) minLevel() (slog.Level, error) {
+	if f.quiet {
+		return slog.LevelWarn, nil
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(f.level)); err != nil {
+		return 0, fmt.Errorf("invalid log level %q, available: debug, info, warn, error", f.level)
+	}
+	return level, nil
+}
+
+// Check the values of the flags, so the logger can be created later without failing
+func (f *logFlags) check() error {
+	if _, err := f.minLevel(); err != nil {
+		return err
+	}
+	for _, format := range logFormats {
+		if f.format == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log format %q, available: %s", f.format, strings.Join(logFormats, ", "))
+}
+
+// Create a logger writing to w as the flags say
+// Invalid flags fall back to the text format and the info level, so the logger can report them after check fails
+func (f *logFlags) newLogger(w io.Writer) *slog.Logger {
+	level, _ := f.minLevel()
+	opts := &slog.HandlerOptions{Level: level}
+	if f.format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// Log an error that stops the command and exit with a failure status
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// Logs the events of the runs of a configuration: when they start, their milestones, their solutions and when they finish
+// The solver calls its hooks one at a time, so it doesn't need to be safe for concurrent use
+type runLogger struct {
+	logger       *slog.Logger
+	milestone    int // Number of generations between milestones
+	bestPossible int
+	started      map[int]bool
+}
+
+func newRunLogger(logger *slog.Logger, cfg nqueens.Config) *runLogger {
+	return &runLogger{
+		logger:       logger,
+		milestone:    max(cfg.MaxGenerations/numMilestones, 1),
+		bestPossible: cfg.BestPossibleFitness(),
+		started:      map[int]bool{},
+	}
+}
+
+// Log the events of a run given its progress
+func (l *runLogger) observe(p nqueens.Progress) {
+	logger := l.logger.With("worker", p.RunID)
+
+	// The first progress of a run is reported when it starts, runs resumed after finishing only report their end
+	if !l.started[p.RunID] {
+		l.started[p.RunID] = true
+		logger.Info("run started", "generation", p.Generation, "max_generations", p.MaxGenerations)
+		if !p.Done() {
+			return
+		}
+	}
+
+	if !p.Done() {
+		if p.Generation%l.milestone == 0 {
+			logger.Debug("generation milestone",
+				"generation", p.Generation,
+				"best_fitness", p.Best.BestFitness,
+				"mean_fitness", p.Current.MeanFitness,
+				"diversity", p.Current.Diversity,
+				"elapsed", p.Elapsed)
+		}
+		return
+	}
+
+	if p.StopReason == nqueens.StopSolution {
+		logger.Info("solution found", "generation", p.Best.Generation, "queen_positions", p.Best.BestQueenPositions, "elapsed", p.Elapsed)
+	}
+	logger.Info("run finished",
+		"stop_reason", p.StopReason,
+		"generations", p.Generation,
+		"best_generation", p.Best.Generation,
+		"best_fitness", p.Best.BestFitness,
+		"best_possible_fitness", l.bestPossible,
+		"best_queen_positions", p.Best.BestQueenPositions,
+		"elapsed", p.Elapsed)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+func Test_logFlags_check(t *testing.T) {
+	tests := []struct {
+		name    string
+		flags   logFlags
+		wantErr bool
+	}{
+		{"Text", logFlags{format: "text", level: "info"}, false},
+		{"JSON debug", logFlags{format: "json", level: "debug"}, false},
+		{"Quiet", logFlags{format: "text", level: "info", quiet: true}, false},
+		{"Unknown format", logFlags{format: "xml", level: "info"}, true},
+		{"Unknown level", logFlags{format: "text", level: "verbose"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.flags.check(); (err != nil) != tt.wantErr {
+				t.Errorf("logFlags.check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Decode every JSON log line
+func decodeLogs(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	t.Helper()
+	logs := []map[string]any{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode log %q: %v", line, err)
+		}
+		logs = append(logs, entry)
+	}
+	return logs
+}
+
+func Test_runLogger(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.NumQueens = 8
+	cfg.MaxGenerations = 20
+	progress := []nqueens.Progress{
+		{RunID: 1, Generation: 0, MaxGenerations: 20},
+		{RunID: 1, Generation: 1, MaxGenerations: 20},
+		{RunID: 1, Generation: 2, MaxGenerations: 20, Best: result.GenerationResult{Generation: 2, BestFitness: 27}},
+		{RunID: 1, Generation: 3, MaxGenerations: 20},
+		{RunID: 1, Generation: 4, MaxGenerations: 20, Best: result.GenerationResult{Generation: 4, BestFitness: 28, BestQueenPositions: []int{3, 1, 6, 2, 5, 7, 4, 0}}, StopReason: result.StopSolution},
+	}
+
+	tests := []struct {
+		name  string
+		flags logFlags
+		want  []string
+	}{
+		{"Debug", logFlags{format: "json", level: "debug"}, []string{"run started", "generation milestone", "solution found", "run finished"}},
+		{"Info", logFlags{format: "json", level: "info"}, []string{"run started", "solution found", "run finished"}},
+		{"Quiet", logFlags{format: "json", level: "debug", quiet: true}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			l := newRunLogger(tt.flags.newLogger(&buf).With("config_hash", cfg.Hash()), cfg)
+			for _, p := range progress {
+				l.observe(p)
+			}
+
+			logs := decodeLogs(t, &buf)
+			got := []string{}
+			for _, entry := range logs {
+				got = append(got, entry["msg"].(string))
+				if entry["worker"] != 1.0 || entry["config_hash"] != cfg.Hash() {
+					t.Errorf("runLogger.observe() logged %v, want the worker and the config hash", entry)
+				}
+			}
+			if strings.Join(got, ", ") != strings.Join(tt.want, ", ") {
+				t.Errorf("runLogger.observe() logged %v, want %v", got, tt.want)
+			}
+			if len(logs) > 0 {
+				last := logs[len(logs)-1]
+				if last["stop_reason"] != "solution" || last["best_fitness"] != 28.0 || last["generations"] != 4.0 {
+					t.Errorf("runLogger.observe() last log = %v, want the finished run", last)
+				}
+			}
+		})
+	}
+}
@@ -1,123 +1,90 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
-	"log"
-	"sync"
-	"time"
-
-	"github.com/dmarts05/genetic-n-queens/internal/config"
-	"github.com/dmarts05/genetic-n-queens/internal/population"
-	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"io"
+	"os"
+	"path/filepath"
 )
 
-func main() {
-	// Load config
-	var help bool
-	var configPath string
-	var numRuns int
-	var populationSize int
-	var maxGenerations int
-	var numQueens int
-	var mutationRate float64
-	var crossOverRate float64
-	var elitism bool
-	var selectionMethodStr string
-	var tournamentSize int
+// Represents a subcommand of the program
+type command struct {
+	name    string
+	summary string
+	run     func(args []string)
+}
 
-	flag.BoolVar(&help, "help", false, "Show help")
-	flag.StringVar(&configPath, "config", "", "Provide the path to a JSON configuration file for the genetic algorithm.")
-	flag.IntVar(&numRuns, "numRuns", config.DefaultConfig.NumRuns, "Number of runs for the genetic algorithm.")
-	flag.IntVar(&populationSize, "populationSize", config.DefaultConfig.PopulationSize, "Population size for the genetic algorithm.")
-	flag.IntVar(&maxGenerations, "maxGenerations", config.DefaultConfig.MaxGenerations, "Maximum number of generations for the genetic algorithm.")
-	flag.IntVar(&numQueens, "numQueens", config.DefaultConfig.NumQueens, "Number of queens for the genetic algorithm.")
-	flag.Float64Var(&mutationRate, "mutationRate", config.DefaultConfig.MutationRate, "Mutation rate for the genetic algorithm.")
-	flag.Float64Var(&crossOverRate, "crossOverRate", config.DefaultConfig.CrossOverRate, "Crossover rate for the genetic algorithm.")
-	flag.BoolVar(&elitism, "elitism", config.DefaultConfig.Elitism, "Elitism for the genetic algorithm.")
-	flag.StringVar(&selectionMethodStr, "selectionMethod", string(config.DefaultConfig.SelectionMethod), "Selection method for the genetic algorithm.")
-	flag.IntVar(&tournamentSize, "tournamentSize", 3, "Tournament size for the tournament selection method.")
-	flag.Parse()
+var commands = []command{
+	{"run", "Run the genetic algorithm", runCommand},
+	{"validate-config", "Check JSON, YAML or TOML configuration files", validateConfigCommand},
+	{"verify", "Check a board or the results of a run for clashes between queens", verifyCommand},
+	{"bench", "Run every combination of several configuration values and compare them", benchCommand},
+	{"experiment", "Run a grid or random sample of configurations from an experiment file and report every one", experimentCommand},
+	{"compare", "Compare the runs of two results files with confidence intervals and significance tests", compareCommand},
+	{"render", "Draw a board as text or SVG", renderCommand},
+	{"schema", "Print the JSON Schema of the configuration files", schemaCommand},
+	{"serve", "Solve configurations submitted over HTTP as queued jobs", serveCommand},
+}
 
-	if help {
-		flag.PrintDefaults()
-		return
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
 
-	var cfg config.Config
-	var err error
-	if configPath == "" {
-		cfg, err = config.New(config.SelectionMethodType(selectionMethodStr), tournamentSize, numRuns, populationSize, maxGenerations, numQueens, mutationRate, crossOverRate, elitism)
-		if err != nil {
-			log.Fatal(err)
-		}
-	} else {
-		cfg, err = config.LoadConfigFromJSON(configPath)
-		if err != nil {
-			log.Fatal(err)
+	name := os.Args[1]
+	switch name {
+	case "help", "-h", "-help", "--help":
+		usage()
+		return
+	}
+	for _, c := range commands {
+		if c.name == name {
+			c.run(os.Args[2:])
+			return
 		}
 	}
 
-	bestPossibleFitness := cfg.NumQueens * (cfg.NumQueens - 1) / 2
-
-	fmt.Println("************************************************************")
-	fmt.Println("Starting genetic algorithm with the following configuration:")
-	fmt.Println("- Number of runs:", cfg.NumRuns)
-	fmt.Println("- Selection method:", cfg.SelectionMethod)
-	fmt.Println("- Population size:", cfg.PopulationSize)
-	fmt.Println("- Maximum number of generations:", cfg.MaxGenerations)
-	fmt.Println("- Number of queens:", cfg.NumQueens)
-	fmt.Println("- Mutation rate:", cfg.MutationRate)
-	fmt.Println("- Crossover rate:", cfg.CrossOverRate)
-	fmt.Println("- Elitism:", cfg.Elitism)
-	fmt.Println("- Best possible fitness:", bestPossibleFitness)
-	fmt.Println("************************************************************")
-
-	fmt.Println()
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(2)
+}
 
-	// Start timer
-	start := time.Now()
+// Name of the executable used in usage messages
+func programName() string {
+	return filepath.Base(os.Args[0])
+}
 
-	// Run the genetic algorithm for the number of runs specified in the configuration with goroutines
-	var wg sync.WaitGroup
-	ch := make(chan result.GenerationResult, cfg.NumRuns)
-	for i := 0; i < cfg.NumRuns; i++ {
-		pop := population.Generate(cfg.NumQueens, cfg.PopulationSize)
-		wg.Add(1)
-		go population.EvolveConcurrentWrapper(i+1, ch, &wg, pop, cfg.SelectionMethod, cfg.MaxGenerations, cfg.MutationRate, cfg.CrossOverRate, cfg.Elitism, bestPossibleFitness)
+// Print the available subcommands
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags]\n\nCommands:\n", programName())
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s %s\n", c.name, c.summary)
 	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -help' to see the flags of a command.\n", programName())
+}
 
-	// Wait for all goroutines to finish
-	wg.Wait()
-	close(ch)
-
-	// Load results from the channel
-	results := []result.GenerationResult{}
-	for r := range ch {
-		results = append(results, r)
+// Create the flag set of a subcommand whose usage message describes its arguments
+func newFlagSet(name, arguments, description string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s %s\n\n%s\n\nFlags:\n", programName(), name, arguments, description)
+		fs.PrintDefaults()
 	}
+	return fs
+}
 
-	elapsed := time.Since(start)
-
-	fmt.Println()
-
-	// Show final results
-	fmt.Println("************************************************************")
-	fmt.Println("Final results:")
-	fmt.Println("- Elapsed time:", elapsed.Seconds(), "seconds")
-	fmt.Println("- Number of solutions found:", result.GetNumSolutions(results))
-	fmt.Println("- Mean number of generations:", result.GetMeanGenerations(results))
-	fmt.Println("- Best fitness:", result.GetBestFitness(results))
-	fmt.Println("- Worst fitness:", result.GetWorstFitness(results))
-	fmt.Println("- Mean of the best fitness:", result.GetMeanBestFitness(results))
-	fmt.Println("- Mean of the mean fitness:", result.GetMeanMeanFitness(results))
-	fmt.Println("************************************************************")
-
-	// Save results to a file
-	fileName := "results.json"
-	err = result.SaveResultsToFile(results, fileName)
+// Write to the file at path with write, or to the standard output if the path is empty
+// The file is created or truncated, and the error closing it is returned as well
+func writeOutput(path string, write func(w io.Writer) error) error {
+	if path == "" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
-	fmt.Println("Results saved to:", fileName)
+	return errors.Join(write(file), file.Close())
 }
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+
+	"github.com/dmarts05/genetic-n-queens/internal/metrics"
+)
+
+// Usage of the -metrics-addr flag shared by the commands exposing metrics
+const metricsAddrUsage = "Address serving the metrics of the runs in the Prometheus text exposition format on /metrics (e.g. localhost:9090). Empty disables metrics."
+
+// Serve the metrics on /metrics of the address in the background until the program exits
+func serveMetrics(addr string, m *metrics.Metrics, logger *slog.Logger) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal(logger, "serving metrics", err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", m)
+	go http.Serve(ln, mux)
+	logger.Info("serving metrics", "url", fmt.Sprintf("http://%v/metrics", ln.Addr()))
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+// Default refresh interval of the dashboard on a terminal and of the progress lines otherwise
+const (
+	terminalRefreshInterval = 250 * time.Millisecond
+	logRefreshInterval      = 10 * time.Second
+)
+
+// Represents the progress of every run, shown as a table redrawn in place on a terminal
+// or as a line per updated run every interval otherwise, e.g. when the output is piped to a file
+type dashboard struct {
+	cfg          nqueens.Config
+	out          io.Writer    // Where the dashboard is drawn
+	logs         io.Writer    // Where the logs are written, above the dashboard
+	logger       *slog.Logger // Logs the progress lines, writing to out rather than through the dashboard
+	terminal     bool
+	interval     time.Duration
+	bestPossible int
+
+	mu      sync.Mutex
+	runs    map[int]nqueens.Progress
+	first   map[int]int  // Generation every run started or was resumed from, elapsed times count from it
+	updated map[int]bool // Runs that have progressed since the last progress lines
+	lines   int          // Number of lines of the dashboard drawn on the terminal
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// Create a dashboard of the runs of the configuration drawn on out, which is redrawn in place if it is a terminal
+// Otherwise the progress lines are logged with the logger, which must write to out
+// A zero interval uses the default interval of the kind of output
+func newDashboard(cfg nqueens.Config, out *os.File, interval time.Duration, logger *slog.Logger) *dashboard {
+	terminal := isTerminal(out)
+	if interval <= 0 {
+		interval = logRefreshInterval
+		if terminal {
+			interval = terminalRefreshInterval
+		}
+	}
+	return &dashboard{
+		cfg:          cfg,
+		out:          out,
+		logs:         out,
+		logger:       logger,
+		terminal:     terminal,
+		interval:     interval,
+		bestPossible: cfg.BestPossibleFitness(),
+		runs:         map[int]nqueens.Progress{},
+		first:        map[int]int{},
+		updated:      map[int]bool{},
+	}
+}
+
+// Check whether a file is a terminal rather than a pipe or a regular file
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Record the progress of a run, it is shown on the next refresh
+func (d *dashboard) observe(p nqueens.Progress) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.first[p.RunID]; !ok {
+		d.first[p.RunID] = p.Generation
+	}
+	d.runs[p.RunID] = p
+	d.updated[p.RunID] = true
+}
+
+// Write logs above the dashboard, so they aren't overwritten when the dashboard is redrawn
+func (d *dashboard) Write(p []byte) (int, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if !d.terminal {
+		return d.logs.Write(p)
+	}
+	d.clear()
+	n, err := d.logs.Write(p)
+	d.draw()
+	return n, err
+}
+
+// Start refreshing the dashboard every interval until it is stopped
+func (d *dashboard) start() {
+	d.stop = make(chan struct{})
+	d.stopped = make(chan struct{})
+	go func() {
+		defer close(d.stopped)
+		ticker := time.NewTicker(d.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.refresh()
+			case <-d.stop:
+				d.refresh()
+				return
+			}
+		}
+	}()
+}
+
+// Stop refreshing the dashboard after showing the last progress of every run
+func (d *dashboard) close() {
+	close(d.stop)
+	<-d.stopped
+}
+
+func (d *dashboard) refresh() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.terminal {
+		d.clear()
+		d.draw()
+		return
+	}
+	d.logProgress()
+}
+
+// Erase the dashboard drawn on the terminal, leaving the cursor where it started
+func (d *dashboard) clear() {
+	if d.lines > 0 {
+		fmt.Fprintf(d.out, "\x1b[%dA\x1b[J", d.lines)
+		d.lines = 0
+	}
+}
+
+// Draw the progress of every run as a table on the terminal
+func (d *dashboard) draw() {
+	var buf bytes.Buffer
+	w := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RUN\tGENERATION\tBEST\tMEAN\tELAPSED\tETA")
+	for _, id := range d.runIDs() {
+		p := d.runs[id]
+		fmt.Fprintf(w, "%v\t%v/%v\t%v/%v\t%.2f\t%v\t%v\n",
+			id, p.Generation, p.MaxGenerations, p.Best.BestFitness, d.bestPossible, p.Current.MeanFitness, p.Elapsed.Round(time.Second), d.eta(p))
+	}
+	w.Flush()
+	d.lines = bytes.Count(buf.Bytes(), []byte("\n"))
+	d.out.Write(buf.Bytes())
+}
+
+// Write a line for every run that has progressed since the last lines
+func (d *dashboard) logProgress() {
+	for _, id := range d.runIDs() {
+		if !d.updated[id] {
+			continue
+		}
+		p := d.runs[id]
+		d.logger.Info("run progress",
+			"worker", id,
+			"generation", p.Generation,
+			"max_generations", p.MaxGenerations,
+			"best_fitness", p.Best.BestFitness,
+			"best_possible_fitness", d.bestPossible,
+			"mean_fitness", p.Current.MeanFitness,
+			"elapsed", p.Elapsed.Round(time.Second),
+			"eta", d.eta(p))
+	}
+	clear(d.updated)
+}
+
+func (d *dashboard) runIDs() []int {
+	ids := make([]int, 0, len(d.runs))
+	for id := range d.runs {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// Describe how long a run will take at most, or why it has stopped
+func (d *dashboard) eta(p nqueens.Progress) string {
+	if p.Done() {
+		return "done (" + string(p.StopReason) + ")"
+	}
+	return estimateRemaining(p, d.first[p.RunID], time.Duration(d.cfg.MaxDuration)).Round(time.Second).String()
+}
+
+// Estimate the time left until a run reaches the maximum number of generations at its current pace,
+// or until it runs out of time if that happens first. Runs can still stop earlier by finding a solution
+// The pace is measured since the first generation, the one the run started or was resumed from, as the elapsed time counts from it
+func estimateRemaining(p nqueens.Progress, first int, maxDuration time.Duration) time.Duration {
+	remaining := time.Duration(0)
+	if evaluated := p.Generation - first; evaluated > 0 {
+		perGeneration := p.Elapsed / time.Duration(evaluated)
+		remaining = perGeneration * time.Duration(p.MaxGenerations-p.Generation)
+	}
+	if maxDuration > 0 {
+		remaining = min(remaining, max(0, maxDuration-p.Elapsed))
+	}
+	return remaining
+}
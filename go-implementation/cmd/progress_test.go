@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+func Test_estimateRemaining(t *testing.T) {
+	tests := []struct {
+		name        string
+		p           nqueens.Progress
+		first       int
+		maxDuration time.Duration
+		want        time.Duration
+	}{
+		{"Generations left", nqueens.Progress{Generation: 10, MaxGenerations: 100, Elapsed: time.Second}, 0, 0, 9 * time.Second},
+		{"Time limit first", nqueens.Progress{Generation: 10, MaxGenerations: 100, Elapsed: time.Second}, 0, 3 * time.Second, 2 * time.Second},
+		{"Time limit exceeded", nqueens.Progress{Generation: 10, MaxGenerations: 100, Elapsed: 4 * time.Second}, 0, 3 * time.Second, 0},
+		{"No generation yet", nqueens.Progress{MaxGenerations: 100}, 0, 0, 0},
+		{"Resumed", nqueens.Progress{Generation: 60, MaxGenerations: 100, Elapsed: time.Second}, 50, 0, 4 * time.Second},
+		{"Just resumed", nqueens.Progress{Generation: 50, MaxGenerations: 100}, 50, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := estimateRemaining(tt.p, tt.first, tt.maxDuration); got != tt.want {
+				t.Errorf("estimateRemaining() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func newTestDashboard(terminal bool) (*dashboard, *bytes.Buffer, *bytes.Buffer) {
+	var out, logs bytes.Buffer
+	cfg := nqueens.DefaultConfig()
+	cfg.NumQueens = 8
+	return &dashboard{
+		cfg:          cfg,
+		out:          &out,
+		logs:         &logs,
+		logger:       slog.New(slog.NewTextHandler(&out, nil)),
+		terminal:     terminal,
+		bestPossible: cfg.BestPossibleFitness(),
+		runs:         map[int]nqueens.Progress{},
+		first:        map[int]int{},
+		updated:      map[int]bool{},
+	}, &out, &logs
+}
+
+func Test_dashboard_logProgress(t *testing.T) {
+	d, out, _ := newTestDashboard(false)
+	d.observe(nqueens.Progress{RunID: 2, Generation: 5, MaxGenerations: 10, Best: nqueens.GenerationResult{BestFitness: 27}})
+	d.observe(nqueens.Progress{RunID: 1, Generation: 10, MaxGenerations: 10, StopReason: nqueens.StopMaxGenerations})
+	d.refresh()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[0], "worker=1 generation=10 max_generations=10") || !strings.Contains(lines[0], `eta="done (max_generations)"`) ||
+		!strings.Contains(lines[1], "worker=2 generation=5 max_generations=10 best_fitness=27 best_possible_fitness=28") {
+		t.Errorf("dashboard.refresh() wrote %q, want a line per run in run order", out.String())
+	}
+
+	// Only runs that progressed since the last lines are written again
+	out.Reset()
+	d.observe(nqueens.Progress{RunID: 2, Generation: 6, MaxGenerations: 10})
+	d.refresh()
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 1 || !strings.Contains(lines[0], "worker=2 generation=6") {
+		t.Errorf("dashboard.refresh() wrote %q, want a line for run 2", out.String())
+	}
+}
+
+func Test_dashboard_terminal(t *testing.T) {
+	d, out, logs := newTestDashboard(true)
+	d.observe(nqueens.Progress{RunID: 1, Generation: 5, MaxGenerations: 10})
+	d.refresh()
+	if d.lines != 2 || !strings.Contains(out.String(), "RUN") {
+		t.Fatalf("dashboard.refresh() drew %q, want a header and a line per run", out.String())
+	}
+
+	// The dashboard is erased before writing logs and drawn again after them
+	out.Reset()
+	d.Write([]byte("level=INFO msg=\"run finished\" worker=1\n"))
+	if logs.String() != "level=INFO msg=\"run finished\" worker=1\n" || !strings.HasPrefix(out.String(), "\x1b[2A\x1b[J") || !strings.Contains(out.String(), "5/10") {
+		t.Errorf("dashboard.Write() wrote %q and drew %q, want the logs above the dashboard", logs.String(), out.String())
+	}
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"strings"
+)
+
+// Draw a board given on the command line or taken from a results file
+func renderCommand(args []string) {
+	fs := newFlagSet("render", "[flags] [board]", "Draw a board, written as the row of the queen of every column (e.g. 1,3,0,2).\nQueens attacked by another queen are drawn as X in text and in red in SVG.")
+	var resultsPath string
+	var runID int
+	var format string
+	var outputPath string
+	fs.StringVar(&resultsPath, "results", "", "Path of a results file to take the board from instead of the arguments.")
+	fs.IntVar(&runID, "run", 0, "Run of the results file to draw. 0 draws the best one.")
+	fs.StringVar(&format, "format", "text", "Output format: text or svg.")
+	fs.StringVar(&outputPath, "output", "", "Path of the file to write the drawing to. Empty writes to the standard output.")
+	fs.Parse(args)
+
+	var board []int
+	var err error
+	if resultsPath != "" {
+		board, err = boardFromResults(resultsPath, runID)
+	} else {
+		board, err = parseBoard(fs.Args())
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var drawing string
+	switch format {
+	case "text":
+		drawing = renderText(board)
+	case "svg":
+		drawing = renderSVG(board)
+	default:
+		log.Fatalf("unknown format %q, must be text or svg", format)
+	}
+
+	err = writeOutput(outputPath, func(w io.Writer) error {
+		_, err := io.WriteString(w, drawing)
+		return err
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Draw a board as text, with a line for every row
+func renderText(board []int) string {
+	conflicted := findClashes(board).Conflicted
+	var sb strings.Builder
+	for row := range board {
+		for col, queenRow := range board {
+			if col > 0 {
+				sb.WriteByte(' ')
+			}
+			switch {
+			case queenRow != row:
+				sb.WriteByte('.')
+			case conflicted[col]:
+				sb.WriteByte('X')
+			default:
+				sb.WriteByte('Q')
+			}
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// Draw a board as an SVG image
+func renderSVG(board []int) string {
+	const cellSize = 32
+	conflicted := findClashes(board).Conflicted
+	size := len(board) * cellSize
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%v\" height=\"%v\" viewBox=\"0 0 %v %v\">\n", size, size, size, size)
+	for row := range board {
+		for col := range board {
+			color := "#f0d9b5"
+			if (row+col)%2 == 1 {
+				color = "#b58863"
+			}
+			fmt.Fprintf(&sb, "<rect x=\"%v\" y=\"%v\" width=\"%v\" height=\"%v\" fill=\"%v\"/>\n", col*cellSize, row*cellSize, cellSize, cellSize, color)
+		}
+	}
+	for col, row := range board {
+		color := "#000000"
+		if conflicted[col] {
+			color = "#d00000"
+		}
+		fmt.Fprintf(&sb, "<text x=\"%v\" y=\"%v\" font-size=\"%v\" text-anchor=\"middle\" dominant-baseline=\"central\" fill=\"%v\">&#9819;</text>\n", col*cellSize+cellSize/2, row*cellSize+cellSize/2, cellSize*3/4, color)
+	}
+	sb.WriteString("</svg>\n")
+	return sb.String()
+}
@@ -0,0 +1,358 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/metrics"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+// Run the genetic algorithm and save the results to the working directory
+func runCommand(args []string) {
+	fs := newFlagSet("run", "[flags]", "Run the genetic algorithm with the given configuration and save the best generation of every run to a results file.")
+
+	// Load config
+	var configPath string
+	var printConfig bool
+	var checkpointPath string
+	var checkpointInterval int
+	var resumePath string
+	var outputPath string
+	var format string
+	var overwrite bool
+	var progress bool
+	var progressInterval time.Duration
+	var metricsAddr string
+
+	fs.StringVar(&configPath, "config", "", "Provide the path to a JSON, YAML (.yaml, .yml) or TOML (.toml) configuration file for the genetic algorithm. Omitted fields keep their default values.")
+	fs.BoolVar(&printConfig, "print-config", false, "Print the configuration merged from the defaults, the configuration file, the environment and the flags, and where every value comes from, then exit.")
+	defineConfigFlags(fs)
+	fs.StringVar(&checkpointPath, "checkpoint", "", "Path of the file where the state of every run is saved periodically so it can be resumed. Empty disables checkpoints.")
+	fs.IntVar(&checkpointInterval, "checkpointInterval", config.DefaultCheckpointInterval, "Number of generations between checkpoints.")
+	fs.StringVar(&resumePath, "resume", "", "Path of a checkpoint to continue from. Its configuration is used and it keeps being updated unless -checkpoint is given.")
+	fs.StringVar(&outputPath, "output", "", "Path of the results file. Defaults to results with the extension of the format in the working directory.")
+	fs.StringVar(&format, "format", string(result.FormatJSON), fmt.Sprintf("Format of the results file. Available: %s.", joinFormats()))
+	fs.BoolVar(&overwrite, "overwrite", false, "Replace the results file if it exists instead of adding a numeric suffix to its name.")
+	fs.BoolVar(&progress, "progress", false, "Show the generation, best and mean fitness and ETA of every run, refreshed in place on a terminal and as log lines on standard error otherwise.")
+	fs.StringVar(&metricsAddr, "metrics-addr", "", metricsAddrUsage)
+	fs.DurationVar(&progressInterval, "progressInterval", 0, fmt.Sprintf("Interval between progress updates. 0 means %v on a terminal and %v otherwise.", terminalRefreshInterval, logRefreshInterval))
+	logs := defineLogFlags(fs)
+	fs.Parse(args)
+
+	logger := logs.newLogger(os.Stderr)
+	if err := logs.check(); err != nil {
+		fatal(logger, "invalid log flags", err)
+	}
+
+	resultFormat := result.Format(format)
+	if !slices.Contains(result.Formats(), resultFormat) {
+		fatal(logger, "invalid format", fmt.Errorf("unknown format %q, available: %s", format, joinFormats()))
+	}
+	if outputPath == "" {
+		outputPath = "results" + resultFormat.Extension()
+	}
+
+	// Build the solver from a checkpoint, or from the layered configuration, with the observers of the runs
+	var solver *nqueens.Solver
+	var err error
+	var dash *dashboard
+	var runLog *runLogger
+	opts := []nqueens.Option{
+		nqueens.OnGeneration(func(p nqueens.Progress) {
+			runLog.observe(p)
+		}),
+	}
+	if progress {
+		opts = append(opts, nqueens.OnGeneration(func(p nqueens.Progress) {
+			dash.observe(p)
+		}))
+	}
+	var m *metrics.Metrics
+	if metricsAddr != "" {
+		m = metrics.New()
+		opts = append(opts, nqueens.OnGeneration(m.Observer("")))
+	}
+	if checkpointPath != "" {
+		opts = append(opts, nqueens.WithCheckpoint(checkpointPath, checkpointInterval))
+	}
+	opts = append(opts, nqueens.OnCheckpointError(func(err error) {
+		logger.Warn("checkpoint not saved", "error", err)
+	}))
+	if resumePath != "" {
+		solver, err = nqueens.Resume(resumePath, opts...)
+	} else {
+		var layered *config.Layered
+		layered, err = loadLayered(fs, configFlags, configPath, logger)
+		if err != nil {
+			fatal(logger, "loading configuration", err)
+		}
+		if printConfig {
+			if err := layered.Print(os.Stdout); err != nil {
+				fatal(logger, "printing configuration", err)
+			}
+			if err := layered.Config.Validate(); err != nil {
+				fatal(logger, "invalid configuration", err)
+			}
+			return
+		}
+		solver, err = nqueens.New(layered.Config, opts...)
+	}
+	if err != nil {
+		fatal(logger, "creating solver", err)
+	}
+	cfg := solver.Config()
+
+	// Log above the progress of every run when it is shown, so the logs aren't overwritten when it is redrawn
+	logger = logger.With("config_hash", cfg.Hash())
+	if progress {
+		dash = newDashboard(cfg, os.Stderr, progressInterval, logger)
+		logger = logs.newLogger(dash).With("config_hash", cfg.Hash())
+	}
+	runLog = newRunLogger(logger, cfg)
+
+	attrs := []any{
+		"runs", cfg.NumRuns,
+		"workers_per_run", cfg.Workers,
+		"selection_method", cfg.SelectionMethod,
+		"crossover", cfg.Crossover,
+		"mutation", cfg.Mutation,
+		"population_size", cfg.PopulationSize,
+		"max_generations", cfg.MaxGenerations,
+		"num_queens", cfg.NumQueens,
+		"mutation_rate", cfg.MutationRate,
+		"gene_mutation_rate", cfg.GeneMutationProbability(),
+		"crossover_rate", cfg.CrossOverRate,
+		"elitism", cfg.Elitism,
+	}
+	if cfg.LocalSearchRate > 0 {
+		attrs = append(attrs, slog.Group("local_search", "mode", cfg.LocalSearchMode, "rate", cfg.LocalSearchRate, "depth", cfg.LocalSearchDepth, "target", cfg.LocalSearchTarget))
+	}
+	if cfg.DetectsStagnation() {
+		attrs = append(attrs, slog.Group("stagnation", "action", cfg.StagnationAction, "restart_fraction", cfg.RestartFraction, "generations", cfg.StagnationGenerations, "diversity_threshold", cfg.DiversityThreshold))
+	}
+	if cfg.IslandMode {
+		attrs = append(attrs, slog.Group("islands", "migration_size", cfg.MigrationSize, "migration_interval", cfg.MigrationInterval, "topology", cfg.MigrationTopology))
+	}
+	attrs = append(attrs,
+		"seed", cfg.Seed,
+		"record_history", cfg.RecordHistory,
+		"max_duration", time.Duration(cfg.MaxDuration),
+		"best_possible_fitness", cfg.BestPossibleFitness())
+	if solver.Resuming() {
+		attrs = append(attrs, "resume", resumePath)
+	}
+	if solver.CheckpointPath() != "" {
+		attrs = append(attrs, "checkpoint", solver.CheckpointPath())
+	}
+	logger.Info("starting genetic algorithm", attrs...)
+
+	// Cancel every run on Ctrl-C so the results found so far can still be saved
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		// Restore the default behavior so a second Ctrl-C kills the process
+		stop()
+	}()
+
+	if m != nil {
+		serveMetrics(metricsAddr, m, logger)
+	}
+
+	if dash != nil {
+		dash.start()
+	}
+	res, err := solver.Solve(ctx)
+	if dash != nil {
+		dash.close()
+	}
+	// Runs stopped by an error keep the generations evaluated before it, so their results are saved before failing
+	failed := err != nil && ctx.Err() == nil
+	switch {
+	case failed:
+		logger.Error("runs failed, saving the results found so far", "error", err)
+	case err != nil:
+		logger.Warn("interrupted, saving the results found so far")
+	}
+
+	logger.Info("finished",
+		"elapsed", res.Elapsed(),
+		"solutions", res.NumSolutions(),
+		"interrupted_runs", res.NumInterrupted(),
+		"mean_generations", res.MeanGenerations(),
+		"best_fitness", res.BestFitness(),
+		"worst_fitness", res.WorstFitness(),
+		"mean_best_fitness", res.MeanBestFitness(),
+		"mean_mean_fitness", res.MeanMeanFitness())
+
+	// Save results to a file, along with how they were produced
+	meta := res.Metadata(os.Args)
+	fileName, err := saveResults(outputPath, overwrite, resultFormat, meta, res.Best())
+	if err != nil {
+		fatal(logger, "saving results", err)
+	}
+	logger.Info("results saved", "path", fileName)
+
+	// Save every generation of every run if requested, next to the results and named after them
+	if cfg.RecordHistory {
+		ext := filepath.Ext(fileName)
+		historyFileName, err := saveResults(strings.TrimSuffix(fileName, ext)+"-history"+ext, overwrite, resultFormat, meta, res.History())
+		if err != nil {
+			fatal(logger, "saving history", err)
+		}
+		logger.Info("history saved", "path", historyFileName)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// Write results to a new file in the given format and return the path it was written to
+func saveResults(path string, overwrite bool, format result.Format, meta result.Metadata, results []result.GenerationResult) (string, error) {
+	return saveFile(path, overwrite, func(w io.Writer) error {
+		return result.WriteResults(w, format, meta, results)
+	})
+}
+
+// Write to a new file with write, without replacing an existing file unless asked to, and return the path it was written to
+func saveFile(path string, overwrite bool, write func(w io.Writer) error) (string, error) {
+	file, err := result.CreateResultFile(path, overwrite)
+	if err != nil {
+		return "", err
+	}
+
+	w := bufio.NewWriter(file)
+	err = write(w)
+	if err == nil {
+		err = w.Flush()
+	}
+	return file.Name(), errors.Join(err, file.Close())
+}
+
+// List the available result formats
+func joinFormats() string {
+	formats := []string{}
+	for _, f := range result.Formats() {
+		formats = append(formats, string(f))
+	}
+	return strings.Join(formats, ", ")
+}
+
+// Flags of the run command that set a configuration field, along with the JSON name of the field
+var configFlags = map[string]string{
+	"numRuns":               "num_runs",
+	"populationSize":        "population_size",
+	"maxGenerations":        "max_generations",
+	"numQueens":             "num_queens",
+	"mutationRate":          "mutation_rate",
+	"crossOverRate":         "crossover_rate",
+	"elitism":               "elitism",
+	"selectionMethod":       "selection_method",
+	"tournamentSize":        "tournament_size",
+	"seed":                  "seed",
+	"history":               "record_history",
+	"maxDuration":           "max_duration",
+	"crossover":             "crossover",
+	"mutation":              "mutation",
+	"geneMutationRate":      "gene_mutation_rate",
+	"islands":               "island_mode",
+	"migrationInterval":     "migration_interval",
+	"migrationSize":         "migration_size",
+	"migrationTopology":     "migration_topology",
+	"localSearchRate":       "local_search_rate",
+	"localSearchDepth":      "local_search_depth",
+	"localSearchMode":       "local_search_mode",
+	"localSearchTarget":     "local_search_target",
+	"stagnationGenerations": "stagnation_generations",
+	"diversityThreshold":    "diversity_threshold",
+	"stagnationAction":      "stagnation_action",
+	"restartFraction":       "restart_fraction",
+	"hypermutationRate":     "hypermutation_rate",
+	"workers":               "workers",
+}
+
+// Define a flag for every configuration field, keyed by flag name in configFlags, with its default value
+func defineConfigFlags(fs *flag.FlagSet) {
+	fs.Int("numRuns", config.DefaultConfig.NumRuns, "Number of runs for the genetic algorithm.")
+	fs.Int("populationSize", config.DefaultConfig.PopulationSize, "Population size for the genetic algorithm.")
+	fs.Int("maxGenerations", config.DefaultConfig.MaxGenerations, "Maximum number of generations for the genetic algorithm.")
+	fs.Int("numQueens", config.DefaultConfig.NumQueens, "Number of queens for the genetic algorithm.")
+	fs.Float64("mutationRate", config.DefaultConfig.MutationRate, "Mutation rate for the genetic algorithm.")
+	fs.Float64("crossOverRate", config.DefaultConfig.CrossOverRate, "Crossover rate for the genetic algorithm.")
+	fs.Bool("elitism", config.DefaultConfig.Elitism, "Elitism for the genetic algorithm.")
+	fs.String("selectionMethod", string(config.DefaultConfig.SelectionMethod), fmt.Sprintf("Selection method for the genetic algorithm. Available: %s.", strings.Join(operator.Selectors(), ", ")))
+	fs.Int("tournamentSize", config.DefaultConfig.TournamentSize, "Tournament size for the tournament selection method.")
+	fs.Uint64("seed", config.DefaultConfig.Seed, "Seed for the random number generator of the genetic algorithm. 0 picks a random seed.")
+	fs.Bool("history", config.DefaultConfig.RecordHistory, "Save every generation of every run to a history file next to the results file.")
+	fs.Duration("maxDuration", time.Duration(config.DefaultConfig.MaxDuration), "Maximum duration of every run of the genetic algorithm (e.g. 1m30s). 0 means no limit.")
+	fs.String("crossover", config.DefaultConfig.Crossover, fmt.Sprintf("Crossover operator for the genetic algorithm. Available: %s.", strings.Join(operator.Crossoverers(), ", ")))
+	fs.String("mutation", config.DefaultConfig.Mutation, fmt.Sprintf("Mutation operator for the genetic algorithm. Available: %s.", strings.Join(operator.Mutators(), ", ")))
+	fs.Float64("geneMutationRate", config.DefaultConfig.GeneMutationRate, "Probability of mutating every queen of a mutated individual. 0 means 2 / number of queens.")
+	fs.Bool("islands", config.DefaultConfig.IslandMode, "Run every run as an island that exchanges its best individuals with its neighbours.")
+	fs.Int("migrationInterval", config.DefaultConfig.MigrationInterval, "Number of generations between migrations in island mode.")
+	fs.Int("migrationSize", config.DefaultConfig.MigrationSize, "Number of individuals sent to every neighbour in island mode.")
+	fs.String("migrationTopology", string(config.DefaultConfig.MigrationTopology), "Topology of the islands: ring, full or random.")
+	fs.Float64("localSearchRate", config.DefaultConfig.LocalSearchRate, "Probability of improving an offspring with min-conflicts local search, or fraction of the best individuals improved when targeting elites. 0 disables local search.")
+	fs.Int("localSearchDepth", config.DefaultConfig.LocalSearchDepth, "Maximum number of moves of every local search.")
+	fs.String("localSearchMode", string(config.DefaultConfig.LocalSearchMode), "Whether local search improvements are written back (lamarckian) or only change the fitness (baldwinian).")
+	fs.String("localSearchTarget", string(config.DefaultConfig.LocalSearchTarget), "Individuals local search is applied to: offspring or elites.")
+	fs.Int("stagnationGenerations", config.DefaultConfig.StagnationGenerations, "Restart part of the population when the best fitness doesn't improve for this many generations. 0 disables it.")
+	fs.Float64("diversityThreshold", config.DefaultConfig.DiversityThreshold, "Restart part of the population when its diversity falls below this value between 0 and 1. 0 disables it.")
+	fs.String("stagnationAction", string(config.DefaultConfig.StagnationAction), "How the population is restarted when it stagnates: reseed or hypermutation.")
+	fs.Float64("restartFraction", config.DefaultConfig.RestartFraction, "Fraction of the worst individuals that are restarted when the population stagnates.")
+	fs.Float64("hypermutationRate", config.DefaultConfig.HypermutationRate, "Probability of mutating every queen of the restarted individuals when using hypermutation.")
+	fs.Int("workers", config.DefaultConfig.Workers, "Number of goroutines evaluating and breeding the population of every run. Results don't depend on it.")
+}
+
+// Build the configuration from the defaults, the configuration file if any, the environment variables and the flags given explicitly, in that order
+// Only the given flags, keyed by flag name, set configuration fields
+// Environment variables with the prefix that don't name a field are ignored with a warning, as they are usually misspelled
+// Values aren't validated until every layer is applied, the solver validates the final configuration
+func loadLayered(fs *flag.FlagSet, flagKeys map[string]string, configPath string, logger *slog.Logger) (*config.Layered, error) {
+	layered := config.NewLayered()
+	if configPath != "" {
+		if err := layered.LoadFile(configPath); err != nil {
+			return nil, err
+		}
+	}
+	unknown, err := layered.LoadEnv(os.Environ())
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range unknown {
+		logger.Warn("ignoring unknown environment variable", "name", name)
+	}
+
+	// Report every invalid flag at once, by flag name
+	invalid := &config.ValidationError{}
+	fs.Visit(func(f *flag.Flag) {
+		key, ok := flagKeys[f.Name]
+		if !ok {
+			return
+		}
+		var fe config.FieldError
+		if err := layered.Set(key, f.Value.String(), config.SourceFlag); errors.As(err, &fe) {
+			invalid.Errors = append(invalid.Errors, config.FieldError{Path: "-" + f.Name, Reason: fe.Reason})
+		}
+	})
+	if len(invalid.Errors) > 0 {
+		return nil, invalid
+	}
+	return layered, nil
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"log"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+// Print the JSON Schema of the configuration files so editors can autocomplete and validate them
+func schemaCommand(args []string) {
+	fs := newFlagSet("schema", "[flags]", "Print the JSON Schema of the configuration files, which describes JSON, YAML and TOML files alike.\nEditors use it to autocomplete and validate configuration files.")
+	var outputPath string
+	fs.StringVar(&outputPath, "output", "", "Path of the file the schema is written to. Empty prints it.")
+	fs.Parse(args)
+
+	if err := writeOutput(outputPath, writeSchema); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// Write the schema of the configuration, describing every field as its flag of the run command does
+// and listing the registered operators as the only valid ones
+func writeSchema(w io.Writer) error {
+	schema := config.JSONSchema()
+
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	defineConfigFlags(flags)
+	for name, key := range configFlags {
+		schema.Properties[key].Description = flags.Lookup(name).Usage
+	}
+	schema.Properties["selection_method"].Enum = operator.Selectors()
+	schema.Properties["crossover"].Enum = operator.Crossoverers()
+	schema.Properties["mutation"].Enum = operator.Mutators()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(schema)
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+)
+
+func Test_writeSchema(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeSchema(&buf); err != nil {
+		t.Fatalf("writeSchema() error = %v", err)
+	}
+
+	var schema config.Schema
+	if err := json.Unmarshal(buf.Bytes(), &schema); err != nil {
+		t.Fatalf("writeSchema() wrote invalid JSON: %v", err)
+	}
+	for _, key := range config.Keys() {
+		p, ok := schema.Properties[key]
+		if !ok || p.Description == "" {
+			t.Errorf("writeSchema() property %v = %+v, want a description", key, p)
+		}
+	}
+	if len(schema.Properties["crossover"].Enum) == 0 {
+		t.Errorf("writeSchema() crossover has no enum, want the registered crossover operators")
+	}
+}
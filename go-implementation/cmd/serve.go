@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/metrics"
+	"github.com/dmarts05/genetic-n-queens/internal/server"
+)
+
+// Time given to the requests in progress to finish when the server stops
+const shutdownTimeout = 5 * time.Second
+
+// Serve the genetic algorithm over HTTP, solving the submitted configurations as queued jobs
+func serveCommand(args []string) {
+	fs := newFlagSet("serve", "[flags]", "Solve configurations submitted over HTTP as jobs. Submit a JSON configuration with POST /jobs, omitted fields keep their default values,\nfollow it with GET /jobs/{id}, cancel it with POST /jobs/{id}/cancel and fetch its results with GET /jobs/{id}/results.")
+	var addr string
+	var metricsAddr string
+	opts := server.DefaultOptions
+	fs.StringVar(&addr, "addr", "localhost:8080", "Address the server listens on.")
+	fs.IntVar(&opts.Workers, "jobs", opts.Workers, "Number of jobs solved at the same time.")
+	fs.IntVar(&opts.QueueSize, "queue", opts.QueueSize, "Number of jobs waiting to be solved, further jobs are rejected until one starts or is cancelled.")
+	fs.IntVar(&opts.Retain, "retain", opts.Retain, "Number of finished jobs whose results are kept, the oldest ones are forgotten first.")
+	fs.IntVar(&opts.Limits.NumRuns, "max-runs", opts.Limits.NumRuns, "Maximum number of runs of a submitted configuration, 0 for no limit.")
+	fs.IntVar(&opts.Limits.PopulationSize, "max-population", opts.Limits.PopulationSize, "Maximum population size of a submitted configuration, 0 for no limit.")
+	fs.IntVar(&opts.Limits.NumQueens, "max-queens", opts.Limits.NumQueens, "Maximum number of queens of a submitted configuration, 0 for no limit.")
+	fs.IntVar(&opts.Limits.MaxGenerations, "max-generations", opts.Limits.MaxGenerations, "Maximum number of generations of a submitted configuration, 0 for no limit.")
+	fs.IntVar(&opts.Limits.Workers, "max-workers", opts.Limits.Workers, "Maximum number of workers of a submitted configuration, 0 for no limit.")
+	fs.StringVar(&metricsAddr, "metrics-addr", "", metricsAddrUsage)
+	logs := defineLogFlags(fs)
+	fs.Parse(args)
+
+	logger := logs.newLogger(os.Stderr)
+	if err := logs.check(); err != nil {
+		fatal(logger, "invalid log flags", err)
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		fatal(logger, "listening", err)
+	}
+
+	if metricsAddr != "" {
+		opts.Metrics = metrics.New()
+		serveMetrics(metricsAddr, opts.Metrics, logger)
+	}
+	opts.Logger = logger
+	srv := server.New(opts)
+	httpServer := &http.Server{Handler: srv}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("listening", "url", fmt.Sprintf("http://%v", ln.Addr()), "workers", max(opts.Workers, 1))
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(ln)
+	}()
+	select {
+	case err := <-serveErr:
+		fatal(logger, "serving", err)
+	case <-ctx.Done():
+	}
+
+	// Cancel the jobs first so the event streams following them end, then wait for the requests in progress
+	srv.Close()
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logger.Warn("shutting down", "error", err)
+	}
+	logger.Info("server stopped")
+}
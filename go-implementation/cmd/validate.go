@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+// Check that every given configuration file can be loaded and is valid
+func validateConfigCommand(args []string) {
+	fs := newFlagSet("validate-config", "<config.json|config.yaml|config.toml>...", "Check that every configuration file only has known fields, valid values and existing operators.\nOmitted fields take their default values.")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	numInvalid := 0
+	for _, path := range fs.Args() {
+		if err := validateConfigFile(path); err != nil {
+			printInvalidConfig(path, err)
+			numInvalid++
+			continue
+		}
+		fmt.Printf("%v: valid configuration\n", path)
+	}
+	if numInvalid > 0 {
+		os.Exit(1)
+	}
+}
+
+// Load a configuration file on top of the defaults, as the run command does, and check that its operators exist
+func validateConfigFile(path string) error {
+	cfg, err := config.LoadFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = operator.FromConfig(cfg)
+	return err
+}
+
+// Print why a configuration file is invalid, one line per invalid field
+func printInvalidConfig(path string, err error) {
+	var ve *config.ValidationError
+	if !errors.As(err, &ve) {
+		fmt.Fprintf(os.Stderr, "%v: %v\n", path, err)
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%v: %v invalid fields\n", path, len(ve.Errors))
+	for _, fe := range ve.Errors {
+		fmt.Fprintf(os.Stderr, "  %v\n", fe)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Check a board for clashes, or check that the results of a file are what their boards really achieve
+func verifyCommand(args []string) {
+	fs := newFlagSet("verify", "[flags] [board]", "Check whether a board, written as the row of the queen of every column (e.g. 1,3,0,2), is a solution.\nExits with status 1 if it isn't, or if any result of -results doesn't match its board.")
+	var resultsPath string
+	fs.StringVar(&resultsPath, "results", "", "Path of a results file whose boards, fitness and solutions are checked instead of a single board.")
+	fs.Parse(args)
+
+	if resultsPath != "" {
+		if !verifyResults(resultsPath) {
+			os.Exit(1)
+		}
+		return
+	}
+
+	board, err := parseBoard(fs.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	report := findClashes(board)
+	if report.Total() == 0 {
+		fmt.Println("The board is a solution: no queens attack each other")
+		return
+	}
+
+	fmt.Printf("The board has %v clashes: %v in the same row and %v on the same diagonal\n", report.Total(), report.RowClashes, report.DiagonalClashes)
+	fmt.Print("Attacked queens in columns:")
+	for col, conflicted := range report.Conflicted {
+		if conflicted {
+			fmt.Print(" ", col)
+		}
+	}
+	fmt.Println()
+	os.Exit(1)
+}
+
+// Check that the fitness and solution flag of every result match its board, returning whether all of them do
+func verifyResults(path string) bool {
+	results, err := result.LoadResultsFromFile(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ok := true
+	for _, r := range results {
+		board := r.BestQueenPositions
+		if err := checkBoard(board); err != nil {
+			fmt.Printf("Run %v: invalid board: %v\n", r.RunID, err)
+			ok = false
+			continue
+		}
+
+		report := findClashes(board)
+		numQueens := len(board)
+		fitness := numQueens*(numQueens-1)/2 - report.Total()
+		switch {
+		case report.RowClashes > 0:
+			fmt.Printf("Run %v: the board has %v queens sharing a row, so it isn't a permutation\n", r.RunID, report.RowClashes)
+			ok = false
+		case fitness != r.BestFitness:
+			fmt.Printf("Run %v: reported fitness %v but the board has fitness %v\n", r.RunID, r.BestFitness, fitness)
+			ok = false
+		case r.IsSolution != (report.Total() == 0):
+			fmt.Printf("Run %v: reported solution %v but the board has %v clashes\n", r.RunID, r.IsSolution, report.Total())
+			ok = false
+		case r.IsSolution:
+			fmt.Printf("Run %v: solution verified\n", r.RunID)
+		default:
+			fmt.Printf("Run %v: fitness %v verified, %v clashes left\n", r.RunID, fitness, report.Total())
+		}
+	}
+	return ok
+}
@@ -1,25 +1,48 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"os"
-	"reflect"
+	"time"
 )
 
 var DefaultConfig = Config{
-	NumRuns:         12,
-	SelectionMethod: Tournament,
-	PopulationSize:  300,
-	MaxGenerations:  3000,
-	NumQueens:       29,
-	MutationRate:    0.2,
-	CrossOverRate:   0.5,
-	Elitism:         false,
-	TournamentSize:  3,
+	NumRuns:               12,
+	SelectionMethod:       Tournament,
+	PopulationSize:        300,
+	MaxGenerations:        3000,
+	NumQueens:             29,
+	MutationRate:          0.2,
+	CrossOverRate:         0.5,
+	Elitism:               false,
+	TournamentSize:        3,
+	Seed:                  0,
+	RecordHistory:         false,
+	MaxDuration:           0,
+	Crossover:             OrderCrossover,
+	Mutation:              SwapMutation,
+	GeneMutationRate:      0,
+	IslandMode:            false,
+	MigrationInterval:     50,
+	MigrationSize:         2,
+	MigrationTopology:     RingTopology,
+	LocalSearchRate:       0,
+	LocalSearchDepth:      10,
+	LocalSearchMode:       Lamarckian,
+	LocalSearchTarget:     Offspring,
+	StagnationGenerations: 0,
+	DiversityThreshold:    0,
+	StagnationAction:      Reseed,
+	RestartFraction:       0.5,
+	HypermutationRate:     0.5,
+	Workers:               1,
 }
 
+// Default number of generations between checkpoints
+const DefaultCheckpointInterval = 100
+
 // Represents the available selection methods for the genetic algorithm
 type SelectionMethodType string
 
@@ -28,113 +51,218 @@ const (
 	Roulette   SelectionMethodType = "roulette"
 )
 
+// Names of the built-in crossover and mutation operators, more can be registered in the operator package
+const (
+	OrderCrossover             = "ox"
+	PartiallyMappedCrossover   = "pmx"
+	CycleCrossover             = "cx"
+	EdgeRecombinationCrossover = "erx"
+	PositionBasedCrossover     = "pbx"
+	SwapMutation               = "swap"
+	InversionMutation          = "inversion"
+	ScrambleMutation           = "scramble"
+	InsertionMutation          = "insertion"
+	ConflictSwapMutation       = "conflict-swap"
+)
+
+// Represents how the islands are connected when migrating individuals in island mode
+type TopologyType string
+
+const (
+	RingTopology   TopologyType = "ring"
+	FullTopology   TopologyType = "full"
+	RandomTopology TopologyType = "random"
+)
+
+// Represents whether the improvements found by local search are written back to the individuals or only used as their fitness
+type LearningType string
+
+const (
+	Lamarckian LearningType = "lamarckian"
+	Baldwinian LearningType = "baldwinian"
+)
+
+// Represents the individuals local search is applied to
+type LocalSearchTargetType string
+
+const (
+	Offspring LocalSearchTargetType = "offspring"
+	Elites    LocalSearchTargetType = "elites"
+)
+
+// Represents what is done to the population when it stagnates
+type StagnationActionType string
+
+const (
+	Reseed        StagnationActionType = "reseed"
+	Hypermutation StagnationActionType = "hypermutation"
+)
+
 // Represents the configuration for the genetic algorithm
 type Config struct {
-	SelectionMethod SelectionMethodType `json:"selection_method"`
-	NumRuns         int                 `json:"num_runs"`
-	PopulationSize  int                 `json:"population_size"`
-	MaxGenerations  int                 `json:"max_generations"`
-	NumQueens       int                 `json:"num_queens"`
-	MutationRate    float64             `json:"mutation_rate"`
-	CrossOverRate   float64             `json:"crossover_rate"`
-	Elitism         bool                `json:"elitism"`
-	TournamentSize  int                 `json:"tournament_size"`
+	SelectionMethod       SelectionMethodType   `json:"selection_method"`
+	NumRuns               int                   `json:"num_runs"`
+	PopulationSize        int                   `json:"population_size"`
+	MaxGenerations        int                   `json:"max_generations"`
+	NumQueens             int                   `json:"num_queens"`
+	MutationRate          float64               `json:"mutation_rate"`
+	CrossOverRate         float64               `json:"crossover_rate"`
+	Elitism               bool                  `json:"elitism"`
+	TournamentSize        int                   `json:"tournament_size"`
+	Seed                  uint64                `json:"seed"`
+	RecordHistory         bool                  `json:"record_history"`
+	MaxDuration           Duration              `json:"max_duration"`
+	Crossover             string                `json:"crossover"`
+	Mutation              string                `json:"mutation"`
+	GeneMutationRate      float64               `json:"gene_mutation_rate"`
+	IslandMode            bool                  `json:"island_mode"`
+	MigrationInterval     int                   `json:"migration_interval"`
+	MigrationSize         int                   `json:"migration_size"`
+	MigrationTopology     TopologyType          `json:"migration_topology"`
+	LocalSearchRate       float64               `json:"local_search_rate"`
+	LocalSearchDepth      int                   `json:"local_search_depth"`
+	LocalSearchMode       LearningType          `json:"local_search_mode"`
+	LocalSearchTarget     LocalSearchTargetType `json:"local_search_target"`
+	StagnationGenerations int                   `json:"stagnation_generations"`
+	DiversityThreshold    float64               `json:"diversity_threshold"`
+	StagnationAction      StagnationActionType  `json:"stagnation_action"`
+	RestartFraction       float64               `json:"restart_fraction"`
+	HypermutationRate     float64               `json:"hypermutation_rate"`
+	Workers               int                   `json:"workers"`
 }
 
-func New(selectionMethod SelectionMethodType, tournamentSize, numRuns, populationSize, maxGenerations, numQueens int, mutationRate, crossOverRate float64, elitism bool) (Config, error) {
-	if selectionMethod != Tournament {
-		tournamentSize = 0
-	}
+// Check whether the population is restarted when it stagnates
+func (c Config) DetectsStagnation() bool {
+	return c.StagnationGenerations > 0 || c.DiversityThreshold > 0
+}
+
+// Represents a duration that is written as a string in configuration files (e.g. "1m30s")
+type Duration time.Duration
 
-	cfg := Config{
-		SelectionMethod: selectionMethod,
-		NumRuns:         numRuns,
-		PopulationSize:  populationSize,
-		MaxGenerations:  maxGenerations,
-		NumQueens:       numQueens,
-		MutationRate:    mutationRate,
-		CrossOverRate:   crossOverRate,
-		Elitism:         elitism,
-		TournamentSize:  tournamentSize,
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"1m30s\": %w", err)
 	}
-	err := cfg.validate()
+	parsed, err := time.ParseDuration(s)
 	if err != nil {
-		return Config{}, err
+		return err
 	}
-
-	return cfg, nil
+	*d = Duration(parsed)
+	return nil
 }
 
-// Validate configuration values
-func (c Config) validate() error {
-	switch {
-	case c.NumRuns < 1:
-		return errors.New("number of runs must be at least 1")
-	case c.PopulationSize < 1:
-		return errors.New("population size must be at least 2")
-	case c.SelectionMethod == Roulette && c.PopulationSize%2 != 0:
-		return errors.New("population size must be even when using the roulette selection method")
-	case c.MaxGenerations < 1:
-		return errors.New("maximum number of generations must be at least 1")
-	case c.NumQueens < 4:
-		return errors.New("number of queens must be at least 4, otherwise the problem is trivial")
-	case c.MutationRate < 0 || c.MutationRate > 1:
-		return errors.New("mutation rate must be between 0 and 1")
-	case c.CrossOverRate < 0 || c.CrossOverRate > 1:
-		return errors.New("crossover rate must be between 0 and 1")
-	case c.SelectionMethod == Tournament && c.TournamentSize < 2:
-		return errors.New("tournament size must be at least 2 when using the tournament selection method")
-	default:
-		return nil
+// Get the probability of mutating every queen of a mutated individual
+// When no rate is configured it is adjusted to the number of queens, so that on average two queens are mutated
+func (c Config) GeneMutationProbability() float64 {
+	if c.GeneMutationRate > 0 {
+		return c.GeneMutationRate
 	}
+	return 2.0 / float64(c.NumQueens)
 }
 
-// Load configuration from specified path or use default configuration if not found
-func LoadConfigFromJSON(path string) (Config, error) {
-	// Load config from specified path
-	data, err := os.ReadFile(path)
-	if err != nil {
-		fmt.Println("load config: specified configuration file not found, falling back to default configuration")
-		return DefaultConfig, nil
-	}
-
-	uncheckedConfig := struct {
-		NumRuns         *int                 `json:"num_runs"`
-		SelectionMethod *SelectionMethodType `json:"selection_method"`
-		PopulationSize  *int                 `json:"population_size"`
-		MaxGenerations  *int                 `json:"max_generations"`
-		NumQueens       *int                 `json:"num_queens"`
-		MutationRate    *float64             `json:"mutation_rate"`
-		CrossOverRate   *float64             `json:"crossover_rate"`
-		Elitism         *bool                `json:"elitism"`
-		TournamentSize  int                  `json:"tournament_size"`
-	}{}
-
-	// Load json into uncheckedConfig
-	err = json.Unmarshal(data, &uncheckedConfig)
-	if err != nil {
-		return Config{}, fmt.Errorf("load config: invalid config file: %w", err)
-	}
+// Get the fitness of a board without clashes, i.e. the number of pairs of queens
+func (c Config) BestPossibleFitness() int {
+	return c.NumQueens * (c.NumQueens - 1) / 2
+}
 
-	// Loop through uncheckedConfig and check if any of the fields are nil
-	v := reflect.ValueOf(uncheckedConfig)
-	for i := 0; i < v.NumField(); i++ {
-		// TournamentSize is not a pointer, so we need to check it separately
-		if i == 8 {
-			continue
-		}
+// Get a short hash identifying the configuration, seed included, to tell apart the logs and results of different configurations
+func (c Config) Hash() string {
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
 
-		if v.Field(i).IsNil() {
-			return Config{}, errors.New("load config: invalid config file")
+// Validate configuration values, reporting every invalid field in a ValidationError
+func (c Config) Validate() error {
+	v := &ValidationError{}
+	if c.NumRuns < 1 {
+		v.add("num_runs", "must be at least 1")
+	}
+	if c.SelectionMethod == "" {
+		v.add("selection_method", "must not be empty")
+	}
+	if c.PopulationSize < 2 {
+		v.add("population_size", "must be at least 2")
+	}
+	if c.SelectionMethod == Roulette && c.PopulationSize%2 != 0 {
+		v.add("population_size", "must be even when using the roulette selection method")
+	}
+	if c.MaxGenerations < 1 {
+		v.add("max_generations", "must be at least 1")
+	}
+	if c.NumQueens < 4 {
+		v.add("num_queens", "must be at least 4, otherwise the problem is trivial")
+	}
+	if c.MutationRate < 0 || c.MutationRate > 1 {
+		v.add("mutation_rate", "must be between 0 and 1")
+	}
+	if c.CrossOverRate < 0 || c.CrossOverRate > 1 {
+		v.add("crossover_rate", "must be between 0 and 1")
+	}
+	if c.SelectionMethod == Tournament && c.TournamentSize < 2 {
+		v.add("tournament_size", "must be at least 2 when using the tournament selection method")
+	}
+	if c.MaxDuration < 0 {
+		v.add("max_duration", "must not be negative")
+	}
+	if c.Crossover == "" {
+		v.add("crossover", "must not be empty")
+	}
+	if c.Mutation == "" {
+		v.add("mutation", "must not be empty")
+	}
+	if c.GeneMutationRate < 0 || c.GeneMutationRate > 1 {
+		v.add("gene_mutation_rate", "must be between 0 and 1")
+	}
+	if c.IslandMode {
+		if c.MigrationInterval < 1 {
+			v.add("migration_interval", "must be at least 1 when using island mode")
+		}
+		if c.MigrationSize < 1 || c.MigrationSize >= c.PopulationSize {
+			v.add("migration_size", "must be at least 1 and smaller than the population size when using island mode")
+		}
+		if c.MigrationTopology != RingTopology && c.MigrationTopology != FullTopology && c.MigrationTopology != RandomTopology {
+			v.add("migration_topology", "must be ring, full or random when using island mode")
 		}
 	}
-
-	// Since there are no nil fields, we can safely load the values into the config struct
-	var cfg Config
-	_ = json.Unmarshal(data, &cfg)
-	err = cfg.validate()
-	if err != nil {
-		return Config{}, err
+	if c.LocalSearchRate < 0 || c.LocalSearchRate > 1 {
+		v.add("local_search_rate", "must be between 0 and 1")
+	}
+	if c.LocalSearchRate > 0 {
+		if c.LocalSearchDepth < 1 {
+			v.add("local_search_depth", "must be at least 1 when using local search")
+		}
+		if c.LocalSearchMode != Lamarckian && c.LocalSearchMode != Baldwinian {
+			v.add("local_search_mode", "must be lamarckian or baldwinian when using local search")
+		}
+		if c.LocalSearchTarget != Offspring && c.LocalSearchTarget != Elites {
+			v.add("local_search_target", "must be offspring or elites when using local search")
+		}
+	}
+	if c.StagnationGenerations < 0 {
+		v.add("stagnation_generations", "must not be negative")
+	}
+	if c.DiversityThreshold < 0 || c.DiversityThreshold > 1 {
+		v.add("diversity_threshold", "must be between 0 and 1")
+	}
+	if c.DetectsStagnation() {
+		if c.StagnationAction != Reseed && c.StagnationAction != Hypermutation {
+			v.add("stagnation_action", "must be reseed or hypermutation when detecting stagnation")
+		}
+		if c.RestartFraction <= 0 || c.RestartFraction > 1 {
+			v.add("restart_fraction", "must be greater than 0 and at most 1 when detecting stagnation")
+		}
+		if c.StagnationAction == Hypermutation && (c.HypermutationRate <= 0 || c.HypermutationRate > 1) {
+			v.add("hypermutation_rate", "must be greater than 0 and at most 1 when using hypermutation")
+		}
+	}
+	if c.Workers < 1 {
+		v.add("workers", "must be at least 1")
 	}
-	return cfg, nil
+	return v.errOrNil()
 }
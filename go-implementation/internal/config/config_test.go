@@ -1,35 +1,28 @@
 package config
 
 import (
+	"encoding/json"
+	"errors"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 )
 
-func TestLoadConfig(t *testing.T) {
-	defaultConfig := Config{
-		NumRuns:         12,
-		SelectionMethod: Tournament,
-		PopulationSize:  300,
-		MaxGenerations:  3000,
-		NumQueens:       29,
-		MutationRate:    0.2,
-		CrossOverRate:   0.5,
-		Elitism:         false,
-		TournamentSize:  3,
-	}
-
-	validConfig := Config{
-		NumRuns:         10,
-		SelectionMethod: Roulette,
-		PopulationSize:  50,
-		MaxGenerations:  300,
-		NumQueens:       22,
-		MutationRate:    0.01,
-		CrossOverRate:   0.1,
-		Elitism:         true,
-		TournamentSize:  0,
-	}
+func TestLoadFile(t *testing.T) {
+	validConfig := DefaultConfig
+	validConfig.NumRuns = 10
+	validConfig.SelectionMethod = Roulette
+	validConfig.PopulationSize = 50
+	validConfig.MaxGenerations = 300
+	validConfig.NumQueens = 22
+	validConfig.MutationRate = 0.01
+	validConfig.CrossOverRate = 0.1
+	validConfig.Elitism = true
+
+	missingFieldConfig := validConfig
+	missingFieldConfig.NumRuns = 1
+	missingFieldConfig.CrossOverRate = DefaultConfig.CrossOverRate
 
 	type args struct {
 		path string
@@ -40,24 +33,123 @@ func TestLoadConfig(t *testing.T) {
 		want    Config
 		wantErr bool
 	}{
-		{"Empty path", args{path: ""}, defaultConfig, false},
-		{"Invalid path", args{path: "invalid"}, defaultConfig, false},
+		{"Empty path", args{path: ""}, Config{}, true},
+		{"Invalid path", args{path: "invalid"}, Config{}, true},
 		{"Valid config", args{path: "valid.json"}, validConfig, false},
-		{"Missing field", args{path: "invalid_missing_field.json"}, Config{}, true},
+		{"Missing field", args{path: "missing_field.json"}, missingFieldConfig, false},
 		{"Invalid field type", args{path: "invalid_field_type.json"}, Config{}, true},
 		{"Invalid field value", args{path: "invalid_value.json"}, Config{}, true},
+		{"Unknown field", args{path: "invalid_unknown_field.json"}, Config{}, true},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tt.args.path = filepath.Join("testdata", tt.args.path)
-			got, err := LoadConfigFromJSON(tt.args.path)
+			got, err := LoadFile(tt.args.path)
 			if (err != nil) != tt.wantErr {
-				t.Errorf("LoadConfig() error = %v, wantErr %v", err, tt.wantErr)
+				t.Errorf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
 				return
 			}
 			if !reflect.DeepEqual(got, tt.want) {
-				t.Errorf("LoadConfig() = %v, want %v", got, tt.want)
+				t.Errorf("LoadFile() = %v, want %v", got, tt.want)
 			}
 		})
 	}
 }
+
+func TestLoadFile_validationError(t *testing.T) {
+	_, err := LoadFile(filepath.Join("testdata", "invalid_multiple.json"))
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("LoadFile() error = %v, want a ValidationError", err)
+	}
+
+	want := []FieldError{
+		{"mutation_rate", "must be of type float64, not string"},
+		{"populaton_size", "unknown field"},
+		{"num_runs", "must be at least 1"},
+		{"population_size", "must be even when using the roulette selection method"},
+		{"num_queens", "must be at least 4, otherwise the problem is trivial"},
+	}
+	if !reflect.DeepEqual(ve.Errors, want) {
+		t.Errorf("LoadFile() errors = %v, want %v", ve.Errors, want)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := DefaultConfig
+
+	invalid := DefaultConfig
+	invalid.Workers = 0
+	invalid.MaxDuration = -1
+	invalid.LocalSearchRate = 0.5
+	invalid.LocalSearchMode = "unknown"
+
+	singleIndividual := DefaultConfig
+	singleIndividual.PopulationSize = 1
+
+	tests := []struct {
+		name   string
+		config Config
+		want   []string
+	}{
+		{"Valid config", valid, nil},
+		{"Invalid config", invalid, []string{"max_duration", "local_search_mode", "workers"}},
+		{"Single individual", singleIndividual, []string{"population_size"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			var got []string
+			var ve *ValidationError
+			if errors.As(err, &ve) {
+				for _, fe := range ve.Errors {
+					got = append(got, fe.Path)
+				}
+			} else if err != nil {
+				t.Fatalf("Config.Validate() error = %v, want a ValidationError", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Config.Validate() invalid fields = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDuration_UnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		want    Duration
+		wantErr bool
+	}{
+		{"Valid duration", `"1m30s"`, Duration(90 * time.Second), false},
+		{"Invalid duration", `"soon"`, 0, true},
+		{"Number", `90`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Duration
+			err := json.Unmarshal([]byte(tt.data), &got)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Duration.UnmarshalJSON() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("Duration.UnmarshalJSON() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfig_Hash(t *testing.T) {
+	cfg := DefaultConfig
+	other := DefaultConfig
+	other.Seed = 1
+
+	if got := cfg.Hash(); len(got) != 16 || got != DefaultConfig.Hash() {
+		t.Errorf("Config.Hash() = %v, want the same 16 hex digits for the same configuration", got)
+	}
+	if cfg.Hash() == other.Hash() {
+		t.Errorf("Config.Hash() = %v for different seeds, want different hashes", cfg.Hash())
+	}
+}
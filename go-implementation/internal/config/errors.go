@@ -0,0 +1,74 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Represents an invalid field of a configuration
+// Path: JSON name of the field, or the name of the environment variable that set it
+type FieldError struct {
+	Path   string
+	Reason string
+}
+
+func (e FieldError) Error() string {
+	return e.Path + ": " + e.Reason
+}
+
+// Represents every invalid field of a configuration, so all of them can be fixed at once
+type ValidationError struct {
+	Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	reasons := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		reasons[i] = fe.Error()
+	}
+	return "invalid configuration: " + strings.Join(reasons, "; ")
+}
+
+// Record an invalid field
+func (e *ValidationError) add(path, reason string) {
+	e.Errors = append(e.Errors, FieldError{Path: path, Reason: reason})
+}
+
+// Record the errors of another validation error, or err itself under the given path
+func (e *ValidationError) merge(path string, err error) {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		e.Errors = append(e.Errors, ve.Errors...)
+		return
+	}
+	var fe FieldError
+	if errors.As(err, &fe) {
+		e.Errors = append(e.Errors, fe)
+		return
+	}
+	e.add(path, err.Error())
+}
+
+// Get the validation error if any field is invalid, or nil otherwise
+func (e *ValidationError) errOrNil() error {
+	if len(e.Errors) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Describe why a JSON value can't be decoded into a field of the given type
+func decodeReason(err error, value json.RawMessage, t reflect.Type) string {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fmt.Sprintf("must be of type %v, not %v", typeErr.Type, typeErr.Value)
+	}
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Sprintf("invalid %v value %s", t, value)
+	}
+	return err.Error()
+}
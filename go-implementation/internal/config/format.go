@@ -0,0 +1,81 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Represents the formats configuration files can be written in
+type FileFormat string
+
+const (
+	JSON FileFormat = "json"
+	YAML FileFormat = "yaml"
+	TOML FileFormat = "toml"
+)
+
+// Get the format of a configuration file from its extension, files without a known extension are JSON
+func FileFormatFromPath(path string) FileFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return YAML
+	case ".toml":
+		return TOML
+	default:
+		return JSON
+	}
+}
+
+// Decode a JSON, YAML or TOML file, in the format given by its extension, into v as if it were JSON
+// Unknown fields are rejected when decoding into a struct
+func ReadFile(path string, v any) error {
+	return readFile(path, FileFormatFromPath(path), v)
+}
+
+func readFile(path string, format FileFormat, v any) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	// YAML and TOML values are written back as JSON, so every format is checked the same way
+	var values map[string]any
+	switch format {
+	case JSON:
+	case YAML:
+		err = yaml.Unmarshal(data, &values)
+	case TOML:
+		err = toml.Unmarshal(data, &values)
+	default:
+		return fmt.Errorf("unknown config file format %q", format)
+	}
+	if err == nil && format != JSON {
+		data, err = json.Marshal(values)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid %v file: %w", format, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return fmt.Errorf("invalid %v file: %w", format, err)
+	}
+	return nil
+}
+
+// Read the fields of a configuration file as JSON values keyed by field name
+func readFields(path string, format FileFormat) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if err := readFile(path, format, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
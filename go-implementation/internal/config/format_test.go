@@ -0,0 +1,79 @@
+package config
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFileFormatFromPath(t *testing.T) {
+	tests := []struct {
+		path string
+		want FileFormat
+	}{
+		{"config.json", JSON},
+		{"config.yaml", YAML},
+		{"config.YML", YAML},
+		{"config.toml", TOML},
+		{"config", JSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			if got := FileFormatFromPath(tt.path); got != tt.want {
+				t.Errorf("FileFormatFromPath() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile_format(t *testing.T) {
+	want, err := LoadFile(filepath.Join("testdata", "valid.json"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		want    Config
+		wantErr bool
+	}{
+		{"JSON", "valid.json", want, false},
+		{"YAML", "valid.yaml", want, false},
+		{"TOML", "valid.toml", want, false},
+		{"Invalid YAML field type", "invalid_field_type.yaml", Config{}, true},
+		{"Invalid TOML syntax", "invalid_syntax.toml", Config{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadFile(filepath.Join("testdata", tt.path))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("LoadFile() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("LoadFile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLayered_LoadFile(t *testing.T) {
+	want := DefaultConfig
+	want.PopulationSize = 100
+	want.NumQueens = 16
+	want.MaxDuration = Duration(time.Minute)
+
+	for _, path := range []string{"partial.json", "partial.yml", "partial.toml"} {
+		t.Run(path, func(t *testing.T) {
+			l := NewLayered()
+			if err := l.LoadFile(filepath.Join("testdata", path)); err != nil {
+				t.Fatalf("Layered.LoadFile() error = %v", err)
+			}
+			if l.Config != want {
+				t.Errorf("Layered.Config = %v, want %v", l.Config, want)
+			}
+		})
+	}
+}
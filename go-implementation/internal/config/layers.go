@@ -0,0 +1,243 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// Represents where the value of a configuration field comes from
+type Source string
+
+const (
+	SourceDefault Source = "default"
+	SourceFile    Source = "file"
+	SourceEnv     Source = "env"
+	SourceFlag    Source = "flag"
+	SourceRequest Source = "request"
+)
+
+// Prefix of the environment variables that set configuration fields, e.g. GNQ_POPULATION_SIZE
+const EnvPrefix = "GNQ_"
+
+// Represents a configuration built from layers applied on top of each other, remembering which layer set every field
+// Layers are meant to be applied in order of precedence: defaults, configuration file, environment variables and flags
+// Fields are identified by their JSON names
+type Layered struct {
+	Config  Config
+	Sources map[string]Source
+}
+
+// Create a layered configuration with the default values
+func NewLayered() *Layered {
+	l := &Layered{Config: DefaultConfig, Sources: map[string]Source{}}
+	for _, key := range Keys() {
+		l.Sources[key] = SourceDefault
+	}
+	return l
+}
+
+// Get the JSON names of every configuration field in declaration order
+func Keys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, t.NumField())
+	for i := range keys {
+		keys[i] = jsonName(t.Field(i))
+	}
+	return keys
+}
+
+// Get the environment variable that sets a configuration field
+func EnvName(key string) string {
+	return EnvPrefix + strings.ToUpper(key)
+}
+
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	return name
+}
+
+// Get the field of the configuration with the given JSON name
+func (l *Layered) field(key string) (reflect.Value, bool) {
+	v := reflect.ValueOf(&l.Config).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if jsonName(v.Type().Field(i)) == key {
+			return v.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// Set a field from its JSON value
+func (l *Layered) setJSON(key string, value json.RawMessage, source Source) error {
+	f, ok := l.field(key)
+	if !ok {
+		return FieldError{Path: key, Reason: "unknown field"}
+	}
+
+	// Decode into a copy so an invalid value doesn't leave the field half set
+	decoded := reflect.New(f.Type())
+	if err := json.Unmarshal(value, decoded.Interface()); err != nil {
+		return FieldError{Path: key, Reason: decodeReason(err, value, f.Type())}
+	}
+	f.Set(decoded.Elem())
+	l.Sources[key] = source
+	return nil
+}
+
+// Set a field from its value written as text, as in environment variables and flags
+func (l *Layered) Set(key, value string, source Source) error {
+	f, ok := l.field(key)
+	if !ok {
+		return FieldError{Path: key, Reason: "unknown field"}
+	}
+
+	// Strings and durations are written as JSON strings, every other value is already valid JSON
+	raw := json.RawMessage(value)
+	if f.Kind() == reflect.String || f.Type() == reflect.TypeOf(Duration(0)) {
+		raw, _ = json.Marshal(value)
+	}
+	return l.setJSON(key, raw, source)
+}
+
+// Copy the configuration, so a layer can be applied to the copy and discarded if any of its values is invalid
+func (l *Layered) clone() *Layered {
+	c := &Layered{Config: l.Config, Sources: make(map[string]Source, len(l.Sources))}
+	for key, source := range l.Sources {
+		c.Sources[key] = source
+	}
+	return c
+}
+
+// Set the JSON values keyed by field name, recording every unknown or invalid field
+// Valid fields are set even if others are invalid
+func (l *Layered) decodeFields(fields map[string]json.RawMessage, source Source, v *ValidationError) {
+	for _, key := range Keys() {
+		if value, ok := fields[key]; ok {
+			if err := l.setJSON(key, value, source); err != nil {
+				v.merge(key, err)
+			}
+		}
+	}
+
+	unknown := []string{}
+	for key := range fields {
+		if _, ok := l.field(key); !ok {
+			unknown = append(unknown, key)
+		}
+	}
+	slices.Sort(unknown)
+	for _, key := range unknown {
+		v.add(key, "unknown field")
+	}
+}
+
+// Apply JSON values keyed by field name, reporting every unknown or invalid field in a ValidationError
+// Nothing is applied if any field is unknown or invalid
+func (l *Layered) ApplyJSON(fields map[string]json.RawMessage, source Source) error {
+	next := l.clone()
+	v := &ValidationError{}
+	next.decodeFields(fields, source, v)
+	if err := v.errOrNil(); err != nil {
+		return err
+	}
+	*l = *next
+	return nil
+}
+
+// Apply the fields of a configuration file in the format given by its extension, any field can be omitted
+// Every unknown or undecodable field is reported in a ValidationError and nothing is applied if there is any
+// Values aren't validated, so later layers can still change them, validate the final configuration instead
+func (l *Layered) LoadFile(path string) error {
+	next := l.clone()
+	v := &ValidationError{}
+	if err := next.decodeFile(path, v); err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := v.errOrNil(); err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	*l = *next
+	return nil
+}
+
+// Load a configuration file on its own, on top of the default configuration, and validate it
+// Every unknown, undecodable and invalid field is reported at once in a ValidationError
+func LoadFile(path string) (Config, error) {
+	l := NewLayered()
+	v := &ValidationError{}
+	if err := l.decodeFile(path, v); err != nil {
+		return Config{}, fmt.Errorf("load config: %w", err)
+	}
+	// Fields that couldn't be decoded keep their valid values, so they aren't reported twice
+	if err := l.Config.Validate(); err != nil {
+		v.merge(path, err)
+	}
+	if err := v.errOrNil(); err != nil {
+		return Config{}, fmt.Errorf("load config: %w", err)
+	}
+	return l.Config, nil
+}
+
+// Decode the fields of a configuration file on top of the layers, reporting every unknown or undecodable field in v
+func (l *Layered) decodeFile(path string, v *ValidationError) error {
+	fields, err := readFields(path, FileFormatFromPath(path))
+	if err != nil {
+		return err
+	}
+	l.decodeFields(fields, SourceFile, v)
+	return nil
+}
+
+// Apply the environment variables starting with the prefix, given as KEY=value like os.Environ returns them
+// Variables that don't name a field are ignored and returned, so they can be warned about
+// Every invalid variable is reported in a ValidationError and nothing is applied if there is any
+func (l *Layered) LoadEnv(environ []string) (unknown []string, err error) {
+	next := l.clone()
+	v := &ValidationError{}
+	for _, kv := range environ {
+		name, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+
+		key := strings.ToLower(strings.TrimPrefix(name, EnvPrefix))
+		if _, ok := next.field(key); !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		if err := next.Set(key, value, SourceEnv); err != nil {
+			var fe FieldError
+			if errors.As(err, &fe) {
+				v.add(name, fe.Reason)
+			}
+		}
+	}
+
+	if err := v.errOrNil(); err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	*l = *next
+	return unknown, nil
+}
+
+// Print every field of the configuration with its value and where it comes from
+func (l *Layered) Print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "FIELD\tVALUE\tSOURCE")
+	v := reflect.ValueOf(l.Config)
+	for i, key := range Keys() {
+		value, err := json.Marshal(v.Field(i).Interface())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(tw, "%v\t%s\t%v\n", key, bytes.TrimSpace(value), l.Sources[key])
+	}
+	return tw.Flush()
+}
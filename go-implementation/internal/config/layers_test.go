@@ -0,0 +1,103 @@
+package config
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLayered(t *testing.T) {
+	l := NewLayered()
+	if err := l.LoadFile(filepath.Join("testdata", "partial.json")); err != nil {
+		t.Fatalf("Layered.LoadFile() error = %v", err)
+	}
+	unknown, err := l.LoadEnv([]string{"HOME=/root", "GNQ_NUM_QUEENS=20", "GNQ_CROSSOVER=pmx", "GNQ_POPULATON_SIZE=10", "GNQ_ELITISM=true"})
+	if err != nil {
+		t.Fatalf("Layered.LoadEnv() error = %v", err)
+	}
+	if strings.Join(unknown, ",") != "GNQ_POPULATON_SIZE" {
+		t.Errorf("Layered.LoadEnv() unknown = %v, want GNQ_POPULATON_SIZE", unknown)
+	}
+	if err := l.Set("crossover", "cx", SourceFlag); err != nil {
+		t.Fatalf("Layered.Set() error = %v", err)
+	}
+	if err := l.Set("max_duration", "30s", SourceFlag); err != nil {
+		t.Fatalf("Layered.Set() error = %v", err)
+	}
+
+	want := DefaultConfig
+	want.PopulationSize = 100
+	want.NumQueens = 20
+	want.Elitism = true
+	want.Crossover = CycleCrossover
+	want.MaxDuration = Duration(30 * time.Second)
+	if l.Config != want {
+		t.Errorf("Layered.Config = %v, want %v", l.Config, want)
+	}
+
+	wantSources := map[string]Source{
+		"num_runs":        SourceDefault,
+		"population_size": SourceFile,
+		"num_queens":      SourceEnv,
+		"elitism":         SourceEnv,
+		"crossover":       SourceFlag,
+		"max_duration":    SourceFlag,
+	}
+	for key, want := range wantSources {
+		if got := l.Sources[key]; got != want {
+			t.Errorf("Layered.Sources[%v] = %v, want %v", key, got, want)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := l.Print(&buf); err != nil {
+		t.Fatalf("Layered.Print() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), `"cx"`) || len(strings.Split(strings.TrimSpace(buf.String()), "\n")) != len(Keys())+1 {
+		t.Errorf("Layered.Print() = %v, want a line for every field", buf.String())
+	}
+}
+
+func TestLayered_LoadFile_override(t *testing.T) {
+	// The file alone is invalid, but a later layer fixes it
+	l := NewLayered()
+	if err := l.LoadFile(filepath.Join("testdata", "invalid_value.json")); err != nil {
+		t.Fatalf("Layered.LoadFile() error = %v", err)
+	}
+	if err := l.Set("num_queens", "8", SourceFlag); err != nil {
+		t.Fatalf("Layered.Set() error = %v", err)
+	}
+	if err := l.Config.Validate(); err != nil || l.Config.NumQueens != 8 || l.Config.NumRuns != 10 {
+		t.Errorf("Layered.Config = %v, %v, want the valid file with 8 queens", l.Config, err)
+	}
+}
+
+func TestLayered_errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		apply func(l *Layered) error
+	}{
+		{"Missing file", func(l *Layered) error { return l.LoadFile(filepath.Join("testdata", "invalid")) }},
+		{"Unknown field in file", func(l *Layered) error { return l.LoadFile(filepath.Join("testdata", "invalid_unknown_field.json")) }},
+		{"Invalid field type in file", func(l *Layered) error { return l.LoadFile(filepath.Join("testdata", "invalid_field_type.json")) }},
+		{"Invalid environment variable", func(l *Layered) error {
+			_, err := l.LoadEnv([]string{"GNQ_NUM_QUEENS=20", "GNQ_POPULATION_SIZE=many"})
+			return err
+		}},
+		{"Invalid duration", func(l *Layered) error { return l.Set("max_duration", "soon", SourceFlag) }},
+		{"Unknown field", func(l *Layered) error { return l.Set("queens", "8", SourceFlag) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLayered()
+			if err := tt.apply(l); err == nil {
+				t.Errorf("%v error = nil, want an error", tt.name)
+			}
+			if l.Config != DefaultConfig {
+				t.Errorf("Layered.Config after error = %v, want the defaults", l.Config)
+			}
+		})
+	}
+}
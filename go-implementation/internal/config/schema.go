@@ -0,0 +1,91 @@
+package config
+
+import (
+	"reflect"
+)
+
+// URI of the JSON Schema version of the generated schemas
+const SchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// Represents a JSON Schema of the configuration files
+// It describes YAML and TOML files too, as they have the same fields
+type Schema struct {
+	Schema               string               `json:"$schema"`
+	Title                string               `json:"title"`
+	Description          string               `json:"description,omitempty"`
+	Type                 string               `json:"type"`
+	Properties           map[string]*Property `json:"properties"`
+	AdditionalProperties bool                 `json:"additionalProperties"`
+}
+
+// Represents a field of the configuration in a JSON Schema
+type Property struct {
+	Type        string   `json:"type"`
+	Description string   `json:"description,omitempty"`
+	Enum        []string `json:"enum,omitempty"`
+	Pattern     string   `json:"pattern,omitempty"`
+	Minimum     *float64 `json:"minimum,omitempty"`
+	Maximum     *float64 `json:"maximum,omitempty"`
+	Default     any      `json:"default"`
+}
+
+// Values of the fields whose type only has a fixed set of values
+var enumValues = map[reflect.Type][]string{
+	reflect.TypeOf(TopologyType("")):          {string(RingTopology), string(FullTopology), string(RandomTopology)},
+	reflect.TypeOf(LearningType("")):          {string(Lamarckian), string(Baldwinian)},
+	reflect.TypeOf(LocalSearchTargetType("")): {string(Offspring), string(Elites)},
+	reflect.TypeOf(StagnationActionType("")):  {string(Reseed), string(Hypermutation)},
+}
+
+// Fields whose values are probabilities or fractions between 0 and 1
+var unitFields = []string{"mutation_rate", "crossover_rate", "gene_mutation_rate", "local_search_rate", "diversity_threshold", "restart_fraction", "hypermutation_rate"}
+
+// Matches the durations accepted by time.ParseDuration, e.g. 1m30s
+const durationPattern = `^[-+]?(0|([0-9]*(\.[0-9]*)?(ns|us|µs|μs|ms|s|m|h))+)$`
+
+// Build the JSON Schema of the configuration files, with the default value of every field
+// Every field is optional, as omitted fields keep their default values, and unknown fields aren't allowed
+func JSONSchema() Schema {
+	schema := Schema{
+		Schema:      SchemaVersion,
+		Title:       "Genetic N-Queens configuration",
+		Description: "Configuration of the genetic algorithm solving the N-Queens problem",
+		Type:        "object",
+		Properties:  map[string]*Property{},
+	}
+
+	defaults := reflect.ValueOf(DefaultConfig)
+	t := defaults.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		p := &Property{Default: defaults.Field(i).Interface()}
+		switch {
+		case f.Type == reflect.TypeOf(Duration(0)):
+			p.Type = "string"
+			p.Pattern = durationPattern
+		case f.Type.Kind() == reflect.String:
+			p.Type = "string"
+			p.Enum = enumValues[f.Type]
+		case f.Type.Kind() == reflect.Bool:
+			p.Type = "boolean"
+		case f.Type.Kind() == reflect.Float64:
+			p.Type = "number"
+		default:
+			p.Type = "integer"
+		}
+		if f.Type.Kind() == reflect.Uint64 {
+			p.Minimum = bound(0)
+		}
+		schema.Properties[jsonName(f)] = p
+	}
+
+	for _, key := range unitFields {
+		schema.Properties[key].Minimum = bound(0)
+		schema.Properties[key].Maximum = bound(1)
+	}
+	return schema
+}
+
+func bound(v float64) *float64 {
+	return &v
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	schema := JSONSchema()
+	if len(schema.Properties) != len(Keys()) {
+		t.Errorf("JSONSchema() has %v properties, want %v", len(schema.Properties), len(Keys()))
+	}
+
+	tests := []struct {
+		key      string
+		wantType string
+		wantEnum []string
+	}{
+		{"num_runs", "integer", nil},
+		{"mutation_rate", "number", nil},
+		{"elitism", "boolean", nil},
+		{"crossover", "string", nil},
+		{"max_duration", "string", nil},
+		{"migration_topology", "string", []string{"ring", "full", "random"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			p, ok := schema.Properties[tt.key]
+			if !ok {
+				t.Fatalf("JSONSchema() has no property %v", tt.key)
+			}
+			if p.Type != tt.wantType || len(p.Enum) != len(tt.wantEnum) {
+				t.Errorf("JSONSchema() property %v = %+v, want type %v and enum %v", tt.key, p, tt.wantType, tt.wantEnum)
+			}
+		})
+	}
+
+	// Defaults are written as they are in configuration files
+	data, err := json.Marshal(schema.Properties["max_duration"].Default)
+	if err != nil || string(data) != `"0s"` {
+		t.Errorf("JSONSchema() max_duration default = %s, %v, want \"0s\"", data, err)
+	}
+}
@@ -0,0 +1,198 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+)
+
+// Represents an experiment comparing configurations, written as a JSON, YAML or TOML file
+// Base: configuration fields shared by every cell, on top of the defaults
+// Grid: values of the fields that change between cells, every combination is a cell unless sampling
+// Sample: if set, only this many random combinations are run
+// Seed: seed of the cells that don't set their own, so they can be compared run by run, 0 picks a random seed
+// Parallel: number of runs executed at the same time, 0 uses one per CPU
+type Experiment struct {
+	Name     string                       `json:"name"`
+	Base     map[string]json.RawMessage   `json:"base"`
+	Grid     map[string][]json.RawMessage `json:"grid"`
+	Sample   *Sample                      `json:"sample"`
+	Seed     uint64                       `json:"seed"`
+	Parallel int                          `json:"parallel"`
+}
+
+// Represents a random sample of the configurations of an experiment
+// Size: number of cells
+// Ranges: fields drawn uniformly between two values, in addition to the grid fields, which are drawn from their values
+type Sample struct {
+	Size   int              `json:"size"`
+	Ranges map[string]Range `json:"ranges"`
+}
+
+// Represents the values a field is drawn from when sampling, integer fields are rounded
+type Range struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
+}
+
+// Represents the value a cell gives to a field of the configuration
+type Param struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Represents a configuration of an experiment
+type Cell struct {
+	ID     int           `json:"id"`
+	Params []Param       `json:"params"`
+	Config config.Config `json:"config"`
+}
+
+// Describe the parameters of the cell as key=value pairs, or as the base configuration if it has none
+func (c Cell) Label() string {
+	if len(c.Params) == 0 {
+		return "base"
+	}
+	pairs := make([]string, len(c.Params))
+	for i, p := range c.Params {
+		pairs[i] = p.Key + "=" + strings.Trim(string(p.Value), `"`)
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Load an experiment from a JSON, YAML or TOML file, in the format given by its extension
+func Load(path string) (Experiment, error) {
+	var e Experiment
+	if err := config.ReadFile(path, &e); err != nil {
+		return Experiment{}, fmt.Errorf("load experiment: %w", err)
+	}
+	return e, nil
+}
+
+// Build the cells of the experiment, every combination of the grid or a random sample of them
+// Cells that don't set a seed in the base or their parameters use the seed of the experiment, so it must be set
+func (e Experiment) Cells() ([]Cell, error) {
+	keys, err := e.keys()
+	if err != nil {
+		return nil, err
+	}
+
+	var params [][]Param
+	if e.Sample != nil {
+		params, err = e.sample(keys)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		params = e.combinations(keys)
+	}
+
+	cells := make([]Cell, len(params))
+	for i, p := range params {
+		layered := config.NewLayered()
+		if err := layered.ApplyJSON(e.Base, config.SourceFile); err != nil {
+			return nil, fmt.Errorf("base: %w", err)
+		}
+		fields := map[string]json.RawMessage{}
+		for _, param := range p {
+			fields[param.Key] = param.Value
+		}
+		if err := layered.ApplyJSON(fields, config.SourceFile); err != nil {
+			return nil, fmt.Errorf("cell %v: %w", i+1, err)
+		}
+
+		cells[i] = Cell{ID: i + 1, Params: p, Config: layered.Config}
+		if layered.Sources["seed"] == config.SourceDefault {
+			cells[i].Config.Seed = e.Seed
+		}
+	}
+	return cells, nil
+}
+
+// Get the fields that change between cells in configuration order, so cells are built the same way every time
+func (e Experiment) keys() ([]string, error) {
+	keys := []string{}
+	for _, key := range config.Keys() {
+		_, inGrid := e.Grid[key]
+		_, inRanges := e.ranges()[key]
+		if inGrid && inRanges {
+			return nil, fmt.Errorf("field %v is both in the grid and in the sample ranges", key)
+		}
+		if inGrid || inRanges {
+			keys = append(keys, key)
+		}
+	}
+
+	for key, values := range e.Grid {
+		if !slices.Contains(keys, key) {
+			return nil, fmt.Errorf("unknown grid field %v", key)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("grid field %v has no values", key)
+		}
+	}
+	for key, r := range e.ranges() {
+		if !slices.Contains(keys, key) {
+			return nil, fmt.Errorf("unknown sample range field %v", key)
+		}
+		if r.Min > r.Max {
+			return nil, fmt.Errorf("sample range of %v has a minimum greater than its maximum", key)
+		}
+	}
+	return keys, nil
+}
+
+func (e Experiment) ranges() map[string]Range {
+	if e.Sample == nil {
+		return nil
+	}
+	return e.Sample.Ranges
+}
+
+// Build every combination of the grid values, the last field changing fastest
+func (e Experiment) combinations(keys []string) [][]Param {
+	combinations := [][]Param{{}}
+	for _, key := range keys {
+		next := make([][]Param, 0, len(combinations)*len(e.Grid[key]))
+		for _, c := range combinations {
+			for _, value := range e.Grid[key] {
+				next = append(next, append(slices.Clone(c), Param{Key: key, Value: value}))
+			}
+		}
+		combinations = next
+	}
+	return combinations
+}
+
+// Draw random combinations of the grid values and sample ranges, using the seed of the experiment
+func (e Experiment) sample(keys []string) ([][]Param, error) {
+	if e.Sample.Size < 1 {
+		return nil, fmt.Errorf("sample size must be at least 1")
+	}
+
+	schema := config.JSONSchema()
+	rng := util.NewRNG(e.Seed, 0)
+	samples := make([][]Param, e.Sample.Size)
+	for i := range samples {
+		for _, key := range keys {
+			var value json.RawMessage
+			if values, ok := e.Grid[key]; ok {
+				value = values[rng.IntN(len(values))]
+			} else {
+				r := e.Sample.Ranges[key]
+				v := r.Min + rng.Float64()*(r.Max-r.Min)
+				if schema.Properties[key].Type == "integer" {
+					v = math.Round(v)
+				}
+				value, _ = json.Marshal(v)
+			}
+			samples[i] = append(samples[i], Param{Key: key, Value: value})
+		}
+	}
+	return samples, nil
+}
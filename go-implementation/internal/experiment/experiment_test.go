@@ -0,0 +1,139 @@
+package experiment
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+)
+
+func TestExperiment_Cells(t *testing.T) {
+	e, err := Load(filepath.Join("testdata", "grid.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cells, err := e.Cells()
+	if err != nil {
+		t.Fatalf("Experiment.Cells() error = %v", err)
+	}
+
+	wantLabels := []string{
+		"selection_method=tournament mutation_rate=0.01",
+		"selection_method=tournament mutation_rate=0.1",
+		"selection_method=tournament mutation_rate=0.2",
+		"selection_method=roulette mutation_rate=0.01",
+		"selection_method=roulette mutation_rate=0.1",
+		"selection_method=roulette mutation_rate=0.2",
+	}
+	gotLabels := []string{}
+	for _, c := range cells {
+		gotLabels = append(gotLabels, c.Label())
+	}
+	if !reflect.DeepEqual(gotLabels, wantLabels) {
+		t.Errorf("Experiment.Cells() labels = %v, want %v", gotLabels, wantLabels)
+	}
+
+	want := config.DefaultConfig
+	want.NumRuns = 3
+	want.NumQueens = 8
+	want.PopulationSize = 20
+	want.MaxGenerations = 50
+	want.SelectionMethod = config.Roulette
+	want.MutationRate = 0.1
+	want.Seed = 7
+	if cells[4].Config != want || cells[4].ID != 5 {
+		t.Errorf("Experiment.Cells()[4] = %+v, want ID 5 and config %+v", cells[4], want)
+	}
+}
+
+func TestExperiment_Cells_sample(t *testing.T) {
+	e, err := Load(filepath.Join("testdata", "sample.toml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cells, err := e.Cells()
+	if err != nil {
+		t.Fatalf("Experiment.Cells() error = %v", err)
+	}
+	if len(cells) != 5 {
+		t.Fatalf("Experiment.Cells() returned %v cells, want 5", len(cells))
+	}
+
+	for _, c := range cells {
+		cfg := c.Config
+		if cfg.Crossover != config.OrderCrossover && cfg.Crossover != config.PartiallyMappedCrossover {
+			t.Errorf("Experiment.Cells() crossover = %v, want ox or pmx", cfg.Crossover)
+		}
+		if cfg.PopulationSize < 10 || cfg.PopulationSize > 100 || cfg.MutationRate < 0 || cfg.MutationRate > 0.5 {
+			t.Errorf("Experiment.Cells() population size = %v and mutation rate = %v, want them within their ranges", cfg.PopulationSize, cfg.MutationRate)
+		}
+	}
+
+	// The sample only depends on the seed
+	again, _ := e.Cells()
+	if !reflect.DeepEqual(cells, again) {
+		t.Errorf("Experiment.Cells() = %v, then %v, want the same cells", cells, again)
+	}
+}
+
+func TestExperiment_Cells_seed(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Experiment
+		want []uint64
+	}{
+		{"Experiment seed", Experiment{Seed: 7, Grid: map[string][]json.RawMessage{"num_queens": {json.RawMessage("8"), json.RawMessage("10")}}}, []uint64{7, 7}},
+		{"Base seed", Experiment{Seed: 7, Base: map[string]json.RawMessage{"seed": json.RawMessage("3")}}, []uint64{3}},
+		{"Grid seed", Experiment{Seed: 7, Grid: map[string][]json.RawMessage{"seed": {json.RawMessage("1"), json.RawMessage("2")}}}, []uint64{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cells, err := tt.e.Cells()
+			if err != nil {
+				t.Fatalf("Experiment.Cells() error = %v", err)
+			}
+			got := []uint64{}
+			for _, c := range cells {
+				got = append(got, c.Config.Seed)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Experiment.Cells() seeds = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExperiment_Cells_errors(t *testing.T) {
+	values := func(s ...string) []json.RawMessage {
+		raw := []json.RawMessage{}
+		for _, v := range s {
+			raw = append(raw, json.RawMessage(v))
+		}
+		return raw
+	}
+
+	tests := []struct {
+		name string
+		e    Experiment
+	}{
+		{"Unknown grid field", Experiment{Grid: map[string][]json.RawMessage{"populaton_size": values("10")}}},
+		{"Empty grid field", Experiment{Grid: map[string][]json.RawMessage{"population_size": {}}}},
+		{"Invalid grid value", Experiment{Grid: map[string][]json.RawMessage{"population_size": values(`"ten"`)}}},
+		{"Invalid base", Experiment{Base: map[string]json.RawMessage{"num_runs": json.RawMessage(`"ten"`)}}},
+		{"Field in grid and ranges", Experiment{
+			Grid:   map[string][]json.RawMessage{"population_size": values("10")},
+			Sample: &Sample{Size: 1, Ranges: map[string]Range{"population_size": {Min: 10, Max: 20}}},
+		}},
+		{"Inverted range", Experiment{Sample: &Sample{Size: 1, Ranges: map[string]Range{"mutation_rate": {Min: 1, Max: 0}}}}},
+		{"Empty sample", Experiment{Sample: &Sample{Size: 0}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.e.Cells(); err == nil {
+				t.Errorf("Experiment.Cells() error = nil, want an error")
+			}
+		})
+	}
+}
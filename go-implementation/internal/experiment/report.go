@@ -0,0 +1,64 @@
+package experiment
+
+import (
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Represents the consolidated results of an experiment
+type Report struct {
+	Name       string       `json:"name"`
+	Seed       uint64       `json:"seed"`
+	StartedAt  time.Time    `json:"started_at"`
+	FinishedAt time.Time    `json:"finished_at"`
+	Cells      []CellReport `json:"cells"`
+}
+
+// Represents the aggregated results of the runs of a cell
+// The fitness and generation aggregates are zero if no run of the cell finished
+type CellReport struct {
+	Cell            Cell                      `json:"cell"`
+	NumRuns         int                       `json:"num_runs"`
+	NumSolutions    int                       `json:"num_solutions"`
+	NumInterrupted  int                       `json:"num_interrupted"`
+	SuccessRate     float64                   `json:"success_rate"`
+	MeanGenerations float64                   `json:"mean_generations"`
+	MeanBestFitness float64                   `json:"mean_best_fitness"`
+	MeanMeanFitness float64                   `json:"mean_mean_fitness"`
+	BestFitness     int                       `json:"best_fitness"`
+	WorstFitness    int                       `json:"worst_fitness"`
+	Duration        time.Duration             `json:"duration_ns"`
+	Results         []result.GenerationResult `json:"results"`
+}
+
+// Aggregate the best generation of every run of a cell
+func NewCellReport(r CellResult) CellReport {
+	results := make([]result.GenerationResult, len(r.Runs))
+	for i, run := range r.Runs {
+		results[i] = run.Best
+	}
+
+	report := CellReport{Cell: r.Cell, NumRuns: len(results), Duration: r.Duration, Results: results}
+	if len(results) == 0 {
+		return report
+	}
+	report.NumSolutions = result.GetNumSolutions(results)
+	report.NumInterrupted = result.GetNumInterrupted(results)
+	report.SuccessRate = float64(report.NumSolutions) / float64(len(results))
+	report.MeanGenerations = result.GetMeanGenerations(results)
+	report.MeanBestFitness = result.GetMeanBestFitness(results)
+	report.MeanMeanFitness = result.GetMeanMeanFitness(results)
+	report.BestFitness = result.GetBestFitness(results)
+	report.WorstFitness = result.GetWorstFitness(results)
+	return report
+}
+
+// Write the report as indented JSON
+func (r Report) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", " ")
+	return encoder.Encode(r)
+}
@@ -0,0 +1,143 @@
+package experiment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+// Represents the results of every run of a cell
+type CellResult struct {
+	Cell     Cell
+	Runs     []result.RunResult
+	Duration time.Duration
+}
+
+// Represents a unit of work of the runner: a run of a cell, or every run of a cell in island mode, as islands evolve together
+type job struct {
+	cell int
+	run  int
+}
+
+// Run every cell NumRuns times with a solver of its configuration, executing up to parallel runs at the same time, or one per CPU if parallel isn't positive
+// Run i of every cell is run i of its solver, as with the run command, so cells are compared on the same random numbers
+// and any cell can be replayed with the run command. Runs that haven't started when the context is cancelled are skipped.
+// done is called, if not nil, when the last run of a cell finishes, one call at a time
+// The solvers of every cell are created before running any of them, so an invalid cell is returned as an error without results.
+// Runs stopped by an error keep their results and their errors are returned joined
+func Run(ctx context.Context, cells []Cell, parallel int, done func(CellResult)) ([]CellResult, error) {
+	solvers := make([]*nqueens.Solver, len(cells))
+	for i, cell := range cells {
+		solver, err := nqueens.New(cell.Config)
+		if err != nil {
+			return nil, fmt.Errorf("cell %v (%v): %w", cell.ID, cell.Label(), err)
+		}
+		solvers[i] = solver
+	}
+
+	results := make([]CellResult, len(cells))
+	remaining := make([]int, len(cells))
+	jobs := []job{}
+	for i, cell := range cells {
+		results[i] = CellResult{Cell: cell, Runs: make([]result.RunResult, cell.Config.NumRuns)}
+		if cell.Config.IslandMode {
+			remaining[i] = 1
+			jobs = append(jobs, job{cell: i, run: -1})
+			continue
+		}
+		remaining[i] = cell.Config.NumRuns
+		for run := 0; run < cell.Config.NumRuns; run++ {
+			jobs = append(jobs, job{cell: i, run: run})
+		}
+	}
+
+	var mu, doneMu sync.Mutex
+	started := make([]bool, len(cells))
+	start := make([]time.Time, len(cells))
+	errs := make([]error, len(jobs))
+	runJob := func(i int) {
+		j := jobs[i]
+		if ctx.Err() != nil {
+			return
+		}
+
+		mu.Lock()
+		if !started[j.cell] {
+			started[j.cell] = true
+			start[j.cell] = time.Now()
+		}
+		mu.Unlock()
+
+		// Runs interrupted by the context are reported by their stop reason, only the errors of the runs are kept
+		var runs []result.RunResult
+		if j.run < 0 {
+			res, _ := solvers[j.cell].Solve(ctx)
+			runs = res.Runs
+			copy(results[j.cell].Runs, runs)
+		} else {
+			r, _ := solvers[j.cell].SolveRun(ctx, j.run+1)
+			runs = []result.RunResult{r}
+			results[j.cell].Runs[j.run] = r
+		}
+		for _, r := range runs {
+			if r.Err != nil {
+				errs[i] = errors.Join(errs[i], fmt.Errorf("cell %v (%v): %w", cells[j.cell].ID, cells[j.cell].Label(), r.Err))
+			}
+		}
+
+		mu.Lock()
+		remaining[j.cell]--
+		last := remaining[j.cell] == 0
+		if last {
+			results[j.cell].Duration = time.Since(start[j.cell])
+		}
+		mu.Unlock()
+		if last && done != nil {
+			doneMu.Lock()
+			done(results[j.cell])
+			doneMu.Unlock()
+		}
+	}
+	forEach(parallel, len(jobs), runJob)
+
+	// Drop the runs that were skipped, so every cell only reports runs that happened
+	for i := range results {
+		runs := results[i].Runs[:0]
+		for _, r := range results[i].Runs {
+			if r.Best.RunID != 0 {
+				runs = append(runs, r)
+			}
+		}
+		results[i].Runs = runs
+	}
+	return results, errors.Join(errs...)
+}
+
+// Call fn for every index from 0 to n-1 using up to workers goroutines, or one per CPU if workers isn't positive
+func forEach(workers, n int, fn func(i int)) {
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
@@ -0,0 +1,127 @@
+package experiment
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+func TestRun(t *testing.T) {
+	e, err := Load(filepath.Join("testdata", "grid.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cells, err := e.Cells()
+	if err != nil {
+		t.Fatalf("Experiment.Cells() error = %v", err)
+	}
+
+	done := map[int]bool{}
+	results, err := Run(context.Background(), cells, 4, func(r CellResult) {
+		done[r.Cell.ID] = true
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != len(cells) || len(done) != len(cells) {
+		t.Fatalf("Run() returned %v cells and finished %v, want %v", len(results), len(done), len(cells))
+	}
+
+	for i, r := range results {
+		if len(r.Runs) != 3 {
+			t.Fatalf("Run() cell %v has %v runs, want 3", r.Cell.ID, len(r.Runs))
+		}
+		solver, err := nqueens.New(cells[i].Config)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j, run := range r.Runs {
+			if run.Best.RunID != j+1 {
+				t.Errorf("Run() cell %v run %v has run ID %v", r.Cell.ID, j+1, run.Best.RunID)
+			}
+			// Runs don't depend on how many of them are executed at the same time
+			if want, _ := solver.SolveRun(context.Background(), j+1); !reflect.DeepEqual(run.Best, want.Best) {
+				t.Errorf("Run() cell %v run %v = %+v, want %+v", r.Cell.ID, j+1, run.Best, want.Best)
+			}
+		}
+	}
+
+	report := NewCellReport(results[0])
+	if report.NumRuns != 3 || report.SuccessRate != float64(report.NumSolutions)/3 || report.BestFitness < report.WorstFitness {
+		t.Errorf("NewCellReport() = %+v, want aggregates of 3 runs", report)
+	}
+}
+
+func TestRun_cancelled(t *testing.T) {
+	e, err := Load(filepath.Join("testdata", "grid.yaml"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	cells, _ := e.Cells()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	results, err := Run(ctx, cells, 2, nil)
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	for _, r := range results {
+		if len(r.Runs) != 0 {
+			t.Errorf("Run() after cancel ran %v runs of cell %v, want 0", len(r.Runs), r.Cell.ID)
+		}
+		if report := NewCellReport(r); report.NumRuns != 0 {
+			t.Errorf("NewCellReport() = %+v, want no runs", report)
+		}
+	}
+}
+
+func Test_forEach(t *testing.T) {
+	tests := []struct {
+		name    string
+		workers int
+		n       int
+	}{
+		{"More workers than calls", 4, 3},
+		{"Fewer workers than calls", 2, 5},
+		{"No workers", 0, 3},
+		{"Negative workers", -1, 3},
+		{"No calls", 2, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			called := make([]int, tt.n)
+			forEach(tt.workers, tt.n, func(i int) {
+				mu.Lock()
+				called[i]++
+				mu.Unlock()
+			})
+			for i, c := range called {
+				if c != 1 {
+					t.Errorf("forEach() called fn(%v) %v times, want 1", i, c)
+				}
+			}
+		})
+	}
+}
+
+func TestRun_invalidCell(t *testing.T) {
+	valid := config.DefaultConfig
+	valid.NumRuns = 1
+	valid.MaxGenerations = 1
+	invalid := valid
+	invalid.Crossover = "unknown"
+
+	ran := false
+	results, err := Run(context.Background(), []Cell{{ID: 1, Config: valid}, {ID: 2, Config: invalid}}, 2, func(CellResult) {
+		ran = true
+	})
+	if err == nil || results != nil || ran {
+		t.Errorf("Run() = %v, %v, want an error before running any cell", results, err)
+	}
+}
@@ -0,0 +1,246 @@
+package individual
+
+import (
+	"errors"
+	"math/rand/v2"
+)
+
+// Every crossover below only reorders the queens of the parents, so the children are always valid permutations
+
+// Check that both parents have the same amount of queens
+func checkSameLength(ind, other *Individual) error {
+	if len(ind.QueenPositions) != len(other.QueenPositions) {
+		return errors.New("individuals have different number of queens")
+	}
+	return nil
+}
+
+// Select two different random cut points, the first one always being the smallest
+func randomCutPoints(rng *rand.Rand, numQueens int) (int, int) {
+	point1 := rng.IntN(numQueens)
+	point2 := rng.IntN(numQueens - 1)
+	if point2 >= point1 {
+		point2++
+	} else {
+		point1, point2 = point2, point1
+	}
+	return point1, point2
+}
+
+// Get the column of every row in the queen positions
+func columnsByRow(queenPositions []int) []int {
+	columns := make([]int, len(queenPositions))
+	for col, row := range queenPositions {
+		columns[row] = col
+	}
+	return columns
+}
+
+// Perform partially mapped crossover (PMX) between two individuals to create two new individuals
+// Each child keeps a segment of one parent and takes the rest from the other parent, mapping the duplicated queens through the segment
+func (ind *Individual) CrossoverPMX(rng *rand.Rand, other *Individual) (*Individual, *Individual, error) {
+	if err := checkSameLength(ind, other); err != nil {
+		return nil, nil, err
+	}
+
+	point1, point2 := randomCutPoints(rng, len(ind.QueenPositions))
+	child1 := &Individual{QueenPositions: pmx(other.QueenPositions, ind.QueenPositions, point1, point2)}
+	child2 := &Individual{QueenPositions: pmx(ind.QueenPositions, other.QueenPositions, point1, point2)}
+	return child1, child2, nil
+}
+
+// Create a child with the segment of the first parent between the cut points and the rest of the second parent
+func pmx(segmentParent, restParent []int, point1, point2 int) []int {
+	numQueens := len(segmentParent)
+	child := make([]int, numQueens)
+	inSegment := make([]bool, numQueens)
+	segmentColumns := columnsByRow(segmentParent)
+	for i := point1; i < point2; i++ {
+		child[i] = segmentParent[i]
+		inSegment[segmentParent[i]] = true
+	}
+
+	for i := 0; i < numQueens; i++ {
+		if i >= point1 && i < point2 {
+			continue
+		}
+		// Follow the mapping until we find a queen that is not in the segment
+		row := restParent[i]
+		for inSegment[row] {
+			row = restParent[segmentColumns[row]]
+		}
+		child[i] = row
+	}
+
+	return child
+}
+
+// Perform cycle crossover (CX) between two individuals to create two new individuals
+// The columns are split in cycles and the children take the cycles from alternating parents, so every queen keeps the column of one of its parents
+func (ind *Individual) CrossoverCX(rng *rand.Rand, other *Individual) (*Individual, *Individual, error) {
+	if err := checkSameLength(ind, other); err != nil {
+		return nil, nil, err
+	}
+
+	numQueens := len(ind.QueenPositions)
+	child1 := &Individual{QueenPositions: make([]int, numQueens)}
+	child2 := &Individual{QueenPositions: make([]int, numQueens)}
+	columns := columnsByRow(ind.QueenPositions)
+	visited := make([]bool, numQueens)
+	cycle := 0
+	for start := 0; start < numQueens; start++ {
+		if visited[start] {
+			continue
+		}
+
+		for col := start; !visited[col]; col = columns[other.QueenPositions[col]] {
+			visited[col] = true
+			if cycle%2 == 0 {
+				child1.QueenPositions[col] = ind.QueenPositions[col]
+				child2.QueenPositions[col] = other.QueenPositions[col]
+			} else {
+				child1.QueenPositions[col] = other.QueenPositions[col]
+				child2.QueenPositions[col] = ind.QueenPositions[col]
+			}
+		}
+		cycle++
+	}
+
+	return child1, child2, nil
+}
+
+// Perform edge recombination crossover (ERX) between two individuals to create two new individuals
+// The children are built by walking the neighbours that the queens have in either parent, preferring the ones with fewer neighbours left
+func (ind *Individual) CrossoverERX(rng *rand.Rand, other *Individual) (*Individual, *Individual, error) {
+	if err := checkSameLength(ind, other); err != nil {
+		return nil, nil, err
+	}
+
+	child1 := &Individual{QueenPositions: erx(rng, ind.QueenPositions, other.QueenPositions, ind.QueenPositions[0])}
+	child2 := &Individual{QueenPositions: erx(rng, ind.QueenPositions, other.QueenPositions, other.QueenPositions[0])}
+	return child1, child2, nil
+}
+
+// Create a child by edge recombination of both parents starting from the given row
+func erx(rng *rand.Rand, parent1, parent2 []int, start int) []int {
+	numQueens := len(parent1)
+
+	// Build the edge list with the neighbours of every row in both parents, treating them as cycles
+	edges := make([][]int, numQueens)
+	addEdge := func(a, b int) {
+		for _, e := range edges[a] {
+			if e == b {
+				return
+			}
+		}
+		edges[a] = append(edges[a], b)
+	}
+	for _, parent := range [][]int{parent1, parent2} {
+		for i, row := range parent {
+			addEdge(row, parent[(i+numQueens-1)%numQueens])
+			addEdge(row, parent[(i+1)%numQueens])
+		}
+	}
+
+	// Keep track of the rows that are not in the child yet to pick a random one in constant time
+	remaining := make([]int, numQueens)
+	remainingIndex := make([]int, numQueens)
+	for i := range remaining {
+		remaining[i] = i
+		remainingIndex[i] = i
+	}
+	take := func(row int) {
+		last := remaining[len(remaining)-1]
+		remaining[remainingIndex[row]] = last
+		remainingIndex[last] = remainingIndex[row]
+		remaining = remaining[:len(remaining)-1]
+
+		// Remove the row from the edge lists of its neighbours
+		for _, neighbour := range edges[row] {
+			for i, e := range edges[neighbour] {
+				if e == row {
+					edges[neighbour] = append(edges[neighbour][:i], edges[neighbour][i+1:]...)
+					break
+				}
+			}
+		}
+	}
+
+	child := make([]int, 0, numQueens)
+	current := start
+	for {
+		child = append(child, current)
+		take(current)
+		if len(remaining) == 0 {
+			break
+		}
+
+		// Pick the neighbour with the fewest neighbours left, breaking ties randomly
+		next := -1
+		numTies := 0
+		for _, neighbour := range edges[current] {
+			switch {
+			case next == -1 || len(edges[neighbour]) < len(edges[next]):
+				next = neighbour
+				numTies = 1
+			case len(edges[neighbour]) == len(edges[next]):
+				numTies++
+				if rng.IntN(numTies) == 0 {
+					next = neighbour
+				}
+			}
+		}
+		// If there are no neighbours left, continue from a random row
+		if next == -1 {
+			next = remaining[rng.IntN(len(remaining))]
+		}
+		current = next
+	}
+
+	return child
+}
+
+// Perform position-based crossover between two individuals to create two new individuals
+// Each child keeps the queens of one parent in a random set of columns and fills the rest in the order of the other parent
+func (ind *Individual) CrossoverPositionBased(rng *rand.Rand, other *Individual) (*Individual, *Individual, error) {
+	if err := checkSameLength(ind, other); err != nil {
+		return nil, nil, err
+	}
+
+	numQueens := len(ind.QueenPositions)
+	selected := make([]bool, numQueens)
+	for i := range selected {
+		selected[i] = rng.IntN(2) == 0
+	}
+
+	child1 := &Individual{QueenPositions: positionBased(ind.QueenPositions, other.QueenPositions, selected)}
+	child2 := &Individual{QueenPositions: positionBased(other.QueenPositions, ind.QueenPositions, selected)}
+	return child1, child2, nil
+}
+
+// Create a child with the queens of the first parent in the selected columns and the rest in the order of the second parent
+func positionBased(keptParent, orderParent []int, selected []bool) []int {
+	numQueens := len(keptParent)
+	child := make([]int, numQueens)
+	used := make([]bool, numQueens)
+	for col, isSelected := range selected {
+		if isSelected {
+			child[col] = keptParent[col]
+			used[keptParent[col]] = true
+		}
+	}
+
+	next := 0
+	for col, isSelected := range selected {
+		if isSelected {
+			continue
+		}
+		for used[orderParent[next]] {
+			next++
+		}
+		child[col] = orderParent[next]
+		used[orderParent[next]] = true
+	}
+
+	return child
+}
@@ -0,0 +1,97 @@
+package individual
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// Check whether the queen positions are a permutation of 0..n-1
+func isPermutation(queenPositions []int) bool {
+	seen := make([]bool, len(queenPositions))
+	for _, row := range queenPositions {
+		if row < 0 || row >= len(queenPositions) || seen[row] {
+			return false
+		}
+		seen[row] = true
+	}
+	return true
+}
+
+var crossovers = map[string]func(*Individual, *rand.Rand, *Individual) (*Individual, *Individual, error){
+	"OX":             (*Individual).Crossover,
+	"PMX":            (*Individual).CrossoverPMX,
+	"CX":             (*Individual).CrossoverCX,
+	"ERX":            (*Individual).CrossoverERX,
+	"Position-based": (*Individual).CrossoverPositionBased,
+}
+
+// Property test: every child of every crossover is a valid permutation and the parents are never modified
+func TestCrossover_ValidPermutations(t *testing.T) {
+	for name, crossover := range crossovers {
+		t.Run(name, func(t *testing.T) {
+			for seed := uint64(0); seed < 500; seed++ {
+				rng := rand.New(rand.NewPCG(seed, 1))
+				numQueens := 2 + rng.IntN(40)
+				parent1 := &Individual{QueenPositions: rng.Perm(numQueens)}
+				parent2 := &Individual{QueenPositions: rng.Perm(numQueens)}
+				original1 := slices.Clone(parent1.QueenPositions)
+				original2 := slices.Clone(parent2.QueenPositions)
+
+				child1, child2, err := crossover(parent1, rng, parent2)
+				if err != nil {
+					t.Fatalf("crossover error = %v", err)
+				}
+				if !isPermutation(child1.QueenPositions) || !isPermutation(child2.QueenPositions) {
+					t.Fatalf("crossover of %v and %v = %v and %v, want valid permutations", original1, original2, child1.QueenPositions, child2.QueenPositions)
+				}
+				if !slices.Equal(parent1.QueenPositions, original1) || !slices.Equal(parent2.QueenPositions, original2) {
+					t.Fatalf("crossover modified the parents")
+				}
+			}
+		})
+	}
+}
+
+func TestCrossover_DifferentLengths(t *testing.T) {
+	for name, crossover := range crossovers {
+		t.Run(name, func(t *testing.T) {
+			parent1 := &Individual{QueenPositions: []int{0, 1, 2, 3}}
+			parent2 := &Individual{QueenPositions: []int{0, 1, 2}}
+			if _, _, err := crossover(parent1, rand.New(rand.NewPCG(1, 1)), parent2); err == nil {
+				t.Errorf("crossover of individuals with different number of queens error = nil, want error")
+			}
+		})
+	}
+}
+
+// Property test: in cycle crossover every queen keeps the column it had in one of the parents
+func TestIndividual_CrossoverCXKeepsColumns(t *testing.T) {
+	for seed := uint64(0); seed < 200; seed++ {
+		rng := rand.New(rand.NewPCG(seed, 2))
+		parent1 := &Individual{QueenPositions: rng.Perm(20)}
+		parent2 := &Individual{QueenPositions: rng.Perm(20)}
+		child1, _, _ := parent1.CrossoverCX(rng, parent2)
+		for col, row := range child1.QueenPositions {
+			if row != parent1.QueenPositions[col] && row != parent2.QueenPositions[col] {
+				t.Fatalf("Individual.CrossoverCX() queen in column %v = %v, want %v or %v", col, row, parent1.QueenPositions[col], parent2.QueenPositions[col])
+			}
+		}
+	}
+}
+
+func TestIndividual_CrossoverCX(t *testing.T) {
+	// Classic example: the cycles are {0, 3, 7, 8}, {1, 2, 4, 6} and {5, 9}
+	parent1 := &Individual{QueenPositions: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}}
+	parent2 := &Individual{QueenPositions: []int{8, 4, 6, 7, 2, 9, 1, 0, 3, 5}}
+	child1, child2, err := parent1.CrossoverCX(rand.New(rand.NewPCG(1, 1)), parent2)
+	if err != nil {
+		t.Fatalf("Individual.CrossoverCX() error = %v", err)
+	}
+	if want := []int{0, 4, 6, 3, 2, 5, 1, 7, 8, 9}; !slices.Equal(child1.QueenPositions, want) {
+		t.Errorf("Individual.CrossoverCX() child1 = %v, want %v", child1.QueenPositions, want)
+	}
+	if want := []int{8, 1, 2, 7, 4, 9, 6, 0, 3, 5}; !slices.Equal(child2.QueenPositions, want) {
+		t.Errorf("Individual.CrossoverCX() child2 = %v, want %v", child2.QueenPositions, want)
+	}
+}
@@ -1,40 +1,142 @@
 package individual
 
 import (
-	"errors"
 	"math/rand/v2"
 	"slices"
 )
 
 // Represents an individual in the population
 // QueenPositions: The positions of the queens on the board. Each index in the array represents the column of the queen and the value at that index represents the row of the queen
+// The number of clashes is cached along with the number of queens on every diagonal, so QueenPositions must not be modified directly without calling Invalidate
 type Individual struct {
 	QueenPositions []int
+
+	evaluated     bool
+	clashes       int
+	mainDiagonals []int // Number of queens on every diagonal, indexed by row - col + numQueens - 1
+	antiDiagonals []int // Number of queens on every anti-diagonal, indexed by row + col
+
+	learned        bool
+	learnedFitness int // Fitness reached by local search without modifying the queens (Baldwinian learning)
+}
+
+// Count the queens on every diagonal and the clashes between them
+func (ind *Individual) evaluate() {
+	numQueens := len(ind.QueenPositions)
+	numDiagonals := 2*numQueens - 1
+	if len(ind.mainDiagonals) != numDiagonals {
+		ind.mainDiagonals = make([]int, numDiagonals)
+		ind.antiDiagonals = make([]int, numDiagonals)
+	} else {
+		clear(ind.mainDiagonals)
+		clear(ind.antiDiagonals)
+	}
+
+	// Since every queen is in a different column and row, we only need to check for diagonal attacks
+	// Every queen placed on a diagonal clashes with all the queens already on it
+	ind.clashes = 0
+	for col, row := range ind.QueenPositions {
+		ind.clashes += ind.mainDiagonals[row-col+numQueens-1] + ind.antiDiagonals[row+col]
+		ind.mainDiagonals[row-col+numQueens-1]++
+		ind.antiDiagonals[row+col]++
+	}
+	ind.evaluated = true
+}
+
+// Remove the queen of the given column from the diagonal counters
+func (ind *Individual) removeQueen(col int) {
+	numQueens := len(ind.QueenPositions)
+	row := ind.QueenPositions[col]
+	ind.mainDiagonals[row-col+numQueens-1]--
+	ind.antiDiagonals[row+col]--
+	ind.clashes -= ind.mainDiagonals[row-col+numQueens-1] + ind.antiDiagonals[row+col]
+}
+
+// Add the queen of the given column to the diagonal counters
+func (ind *Individual) addQueen(col int) {
+	numQueens := len(ind.QueenPositions)
+	row := ind.QueenPositions[col]
+	ind.clashes += ind.mainDiagonals[row-col+numQueens-1] + ind.antiDiagonals[row+col]
+	ind.mainDiagonals[row-col+numQueens-1]++
+	ind.antiDiagonals[row+col]++
 }
 
 // Calculate the number of clashes between the queens for the individual
 func (ind *Individual) getNumClashes() int {
+	if !ind.evaluated {
+		ind.evaluate()
+	}
+	return ind.clashes
+}
+
+// Create a deep copy of the individual, including its cached fitness
+func (ind *Individual) Clone() *Individual {
+	return &Individual{
+		QueenPositions: slices.Clone(ind.QueenPositions),
+		evaluated:      ind.evaluated,
+		clashes:        ind.clashes,
+		mainDiagonals:  slices.Clone(ind.mainDiagonals),
+		antiDiagonals:  slices.Clone(ind.antiDiagonals),
+		learned:        ind.learned,
+		learnedFitness: ind.learnedFitness,
+	}
+}
+
+// Discard the cached fitness, must be called after modifying QueenPositions directly
+func (ind *Individual) Invalidate() {
+	ind.evaluated = false
+	ind.learned = false
+}
+
+// Swap the queens of two columns, updating the cached fitness in constant time
+func (ind *Individual) Swap(i, j int) {
+	if i == j {
+		return
+	}
+	ind.learned = false
+	if !ind.evaluated {
+		ind.QueenPositions[i], ind.QueenPositions[j] = ind.QueenPositions[j], ind.QueenPositions[i]
+		return
+	}
+
+	ind.removeQueen(i)
+	ind.removeQueen(j)
+	ind.QueenPositions[i], ind.QueenPositions[j] = ind.QueenPositions[j], ind.QueenPositions[i]
+	ind.addQueen(i)
+	ind.addQueen(j)
+}
+
+// Check whether the queen of the given column shares a diagonal with any other queen
+func (ind *Individual) IsInConflict(col int) bool {
+	if !ind.evaluated {
+		ind.evaluate()
+	}
 	numQueens := len(ind.QueenPositions)
-	clashes := 0
+	row := ind.QueenPositions[col]
+	return ind.mainDiagonals[row-col+numQueens-1] > 1 || ind.antiDiagonals[row+col] > 1
+}
 
-	// Since every queen is in a different column and row, we only need to check for diagonal attacks
-	// If the difference between the rows and columns of the 2 queens are equal, they are attacking each other
-	for col1 := 0; col1 < numQueens; col1++ {
-		for col2 := col1 + 1; col2 < numQueens; col2++ {
-			row1 := ind.QueenPositions[col1]
-			row2 := ind.QueenPositions[col2]
-
-			if row1-col1 == row2-col2 || row1+col1 == row2+col2 {
-				clashes++
-			}
+// Get the columns whose queens share a diagonal with any other queen
+func (ind *Individual) ConflictedColumns() []int {
+	conflicted := []int{}
+	for col := range ind.QueenPositions {
+		if ind.IsInConflict(col) {
+			conflicted = append(conflicted, col)
 		}
 	}
-
-	return clashes
+	return conflicted
 }
 
-// Calculate the fitness of the individual
+// Calculate the fitness of the individual, which is the learned fitness if local search has set one
 func (ind *Individual) Fitness() int {
+	if ind.learned {
+		return ind.learnedFitness
+	}
+	return ind.GenotypeFitness()
+}
+
+// Calculate the fitness of the queen positions of the individual, ignoring any learned fitness
+func (ind *Individual) GenotypeFitness() int {
 	numQueens := len(ind.QueenPositions)
 	maxNonAttackingPairs := numQueens * (numQueens - 1) / 2
 	clashes := ind.getNumClashes()
@@ -42,12 +144,24 @@ func (ind *Individual) Fitness() int {
 	return fitness
 }
 
+// Set the fitness reached by local search without writing the improved queens back (Baldwinian learning)
+// It is discarded as soon as the queens are modified
+func (ind *Individual) SetLearnedFitness(fitness int) {
+	ind.learned = true
+	ind.learnedFitness = fitness
+}
+
+// Get the fitness set by local search and whether there is one
+func (ind *Individual) LearnedFitness() (int, bool) {
+	return ind.learnedFitness, ind.learned
+}
+
 // Perform crossover between two individuals to create two new individuals
 // Here we are using OX because it let us avoid creating invalid individuals (i.e. individuals with duplicate queen positions or in the same row or column
-func (ind *Individual) Crossover(other *Individual) (*Individual, *Individual, error) {
+func (ind *Individual) Crossover(rng *rand.Rand, other *Individual) (*Individual, *Individual, error) {
 	// Check if the two individuals have the same amount of queens
-	if len(ind.QueenPositions) != len(other.QueenPositions) {
-		return nil, nil, errors.New("individuals have different number of queens")
+	if err := checkSameLength(ind, other); err != nil {
+		return nil, nil, err
 	}
 
 	// Create two new individuals to store the children
@@ -61,13 +175,7 @@ func (ind *Individual) Crossover(other *Individual) (*Individual, *Individual, e
 	}
 
 	// Select two random points to perform the crossover
-	point1 := rand.IntN(numQueens)
-	point2 := rand.IntN(numQueens - 1)
-	if point2 >= point1 {
-		point2++
-	} else {
-		point1, point2 = point2, point1
-	}
+	point1, point2 := randomCutPoints(rng, numQueens)
 
 	// Copy the selected part of the parents to the children
 	for i := point1; i < point2; i++ {
@@ -105,15 +213,15 @@ func (ind *Individual) Crossover(other *Individual) (*Individual, *Individual, e
 }
 
 // Mutate the individual by shuffling each queen position with a certain probability
-func (ind *Individual) Mutate(individualProbability float64) {
+func (ind *Individual) Mutate(rng *rand.Rand, individualProbability float64) {
 	numQueens := len(ind.QueenPositions)
 	for i := 0; i < numQueens; i++ {
-		if rand.Float64() < individualProbability {
-			swapIndex := rand.IntN(numQueens - 1)
+		if rng.Float64() < individualProbability {
+			swapIndex := rng.IntN(numQueens - 1)
 			if swapIndex >= i {
 				swapIndex++
 			}
-			ind.QueenPositions[i], ind.QueenPositions[swapIndex] = ind.QueenPositions[swapIndex], ind.QueenPositions[i]
+			ind.Swap(i, swapIndex)
 		}
 	}
 }
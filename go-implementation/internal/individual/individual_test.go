@@ -1,6 +1,8 @@
 package individual
 
 import (
+	"math/rand/v2"
+	"slices"
 	"testing"
 )
 
@@ -50,3 +52,73 @@ func TestIndividual_Fitness(t *testing.T) {
 		})
 	}
 }
+
+// Golden test: the same seed must always produce the same children
+func TestIndividual_CrossoverGolden(t *testing.T) {
+	rng := rand.New(rand.NewPCG(42, 1))
+	parent1 := &Individual{QueenPositions: []int{0, 1, 2, 3, 4, 5, 6, 7}}
+	parent2 := &Individual{QueenPositions: []int{7, 6, 5, 4, 3, 2, 1, 0}}
+
+	child1, child2, err := parent1.Crossover(rng, parent2)
+	if err != nil {
+		t.Fatalf("Individual.Crossover() error = %v", err)
+	}
+	if want := []int{0, 1, 2, 4, 3, 5, 6, 7}; !slices.Equal(child1.QueenPositions, want) {
+		t.Errorf("Individual.Crossover() child1 = %v, want %v", child1.QueenPositions, want)
+	}
+	if want := []int{7, 6, 5, 3, 4, 2, 1, 0}; !slices.Equal(child2.QueenPositions, want) {
+		t.Errorf("Individual.Crossover() child2 = %v, want %v", child2.QueenPositions, want)
+	}
+}
+
+// Golden test: the same seed must always produce the same mutation
+func TestIndividual_MutateGolden(t *testing.T) {
+	rng := rand.New(rand.NewPCG(42, 1))
+	ind := &Individual{QueenPositions: []int{0, 1, 2, 3, 4, 5, 6, 7}}
+	ind.Mutate(rng, 0.5)
+	if want := []int{3, 5, 2, 0, 4, 1, 6, 7}; !slices.Equal(ind.QueenPositions, want) {
+		t.Errorf("Individual.Mutate() = %v, want %v", ind.QueenPositions, want)
+	}
+}
+
+// Count the clashes between every pair of queens to check the cached fitness against
+func bruteForceFitness(queenPositions []int) int {
+	numQueens := len(queenPositions)
+	clashes := 0
+	for col1 := 0; col1 < numQueens; col1++ {
+		for col2 := col1 + 1; col2 < numQueens; col2++ {
+			row1 := queenPositions[col1]
+			row2 := queenPositions[col2]
+			if row1-col1 == row2-col2 || row1+col1 == row2+col2 {
+				clashes++
+			}
+		}
+	}
+	return numQueens*(numQueens-1)/2 - clashes
+}
+
+func TestIndividual_SwapUpdatesFitness(t *testing.T) {
+	rng := rand.New(rand.NewPCG(7, 1))
+	numQueens := 30
+	ind := &Individual{QueenPositions: rng.Perm(numQueens)}
+	ind.Fitness()
+
+	for i := 0; i < 1000; i++ {
+		ind.Swap(rng.IntN(numQueens), rng.IntN(numQueens))
+		if got, want := ind.Fitness(), bruteForceFitness(ind.QueenPositions); got != want {
+			t.Fatalf("Individual.Fitness() after swap = %v, want %v", got, want)
+		}
+	}
+
+	ind.Mutate(rng, 0.5)
+	if got, want := ind.Fitness(), bruteForceFitness(ind.QueenPositions); got != want {
+		t.Errorf("Individual.Fitness() after mutation = %v, want %v", got, want)
+	}
+
+	// Direct modifications are only picked up after invalidating the cache
+	ind.QueenPositions[0], ind.QueenPositions[1] = ind.QueenPositions[1], ind.QueenPositions[0]
+	ind.Invalidate()
+	if got, want := ind.Fitness(), bruteForceFitness(ind.QueenPositions); got != want {
+		t.Errorf("Individual.Fitness() after invalidation = %v, want %v", got, want)
+	}
+}
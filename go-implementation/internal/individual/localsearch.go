@@ -0,0 +1,58 @@
+package individual
+
+import "math/rand/v2"
+
+// Improve the individual with min-conflicts descent: a random queen in conflict is swapped with the column that reduces the clashes the most
+// Queens in conflict are tried in random order until one of them can be improved, so the search stops at a local optimum or after the maximum number of moves
+// Returns the number of moves performed
+func (ind *Individual) MinConflicts(rng *rand.Rand, maxMoves int) int {
+	numQueens := len(ind.QueenPositions)
+	moves := 0
+	for moves < maxMoves {
+		conflicted := ind.ConflictedColumns()
+		rng.Shuffle(len(conflicted), func(i, j int) {
+			conflicted[i], conflicted[j] = conflicted[j], conflicted[i]
+		})
+
+		improved := false
+		for _, col := range conflicted {
+			// Try every swap in constant time thanks to the cached diagonal counters, breaking ties randomly
+			current := ind.GenotypeFitness()
+			bestFitness := current
+			bestCol := -1
+			numTies := 0
+			for other := 0; other < numQueens; other++ {
+				if other == col {
+					continue
+				}
+				ind.Swap(col, other)
+				fitness := ind.GenotypeFitness()
+				ind.Swap(col, other)
+
+				switch {
+				case fitness > bestFitness:
+					bestFitness = fitness
+					bestCol = other
+					numTies = 1
+				case fitness == bestFitness && bestCol != -1:
+					numTies++
+					if rng.IntN(numTies) == 0 {
+						bestCol = other
+					}
+				}
+			}
+
+			if bestCol != -1 {
+				ind.Swap(col, bestCol)
+				improved = true
+				break
+			}
+		}
+
+		if !improved {
+			break
+		}
+		moves++
+	}
+	return moves
+}
@@ -0,0 +1,49 @@
+package individual
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestIndividual_MinConflicts(t *testing.T) {
+	for seed := uint64(0); seed < 100; seed++ {
+		rng := rand.New(rand.NewPCG(seed, 1))
+		ind := &Individual{QueenPositions: rng.Perm(30)}
+		before := ind.Fitness()
+
+		moves := ind.MinConflicts(rng, 10)
+		if moves > 10 {
+			t.Fatalf("Individual.MinConflicts() performed %v moves, want at most 10", moves)
+		}
+		if !isPermutation(ind.QueenPositions) {
+			t.Fatalf("Individual.MinConflicts() = %v, want a valid permutation", ind.QueenPositions)
+		}
+		// Every move is strictly improving
+		if got := ind.Fitness(); got < before+moves || got != bruteForceFitness(ind.QueenPositions) {
+			t.Fatalf("Individual.Fitness() after %v moves = %v, want at least %v and equal to %v", moves, got, before+moves, bruteForceFitness(ind.QueenPositions))
+		}
+	}
+}
+
+func TestIndividual_SetLearnedFitness(t *testing.T) {
+	ind := &Individual{QueenPositions: []int{0, 1, 2, 3}}
+	ind.SetLearnedFitness(5)
+	if got := ind.Fitness(); got != 5 {
+		t.Errorf("Individual.Fitness() with learned fitness = %v, want 5", got)
+	}
+	if got := ind.GenotypeFitness(); got != 0 {
+		t.Errorf("Individual.GenotypeFitness() with learned fitness = %v, want 0", got)
+	}
+	if got, ok := ind.LearnedFitness(); got != 5 || !ok {
+		t.Errorf("Individual.LearnedFitness() = %v, %v, want 5, true", got, ok)
+	}
+
+	// Modifying the queens discards the learned fitness
+	ind.Swap(0, 1)
+	if got, want := ind.Fitness(), bruteForceFitness(ind.QueenPositions); got != want {
+		t.Errorf("Individual.Fitness() after swap = %v, want %v", got, want)
+	}
+	if _, ok := ind.LearnedFitness(); ok {
+		t.Errorf("Individual.LearnedFitness() after swap is set, want unset")
+	}
+}
@@ -0,0 +1,78 @@
+package individual
+
+import "math/rand/v2"
+
+// Every mutation below moves the queens with Swap, so the cached fitness stays valid and the individual remains a valid permutation
+
+// Get a random column different from the given one
+func randomOtherColumn(rng *rand.Rand, numQueens, col int) int {
+	other := rng.IntN(numQueens - 1)
+	if other >= col {
+		other++
+	}
+	return other
+}
+
+// Mutate the individual by reversing the queens between each column and a random column with a certain probability
+func (ind *Individual) MutateInversion(rng *rand.Rand, individualProbability float64) {
+	numQueens := len(ind.QueenPositions)
+	for i := 0; i < numQueens; i++ {
+		if rng.Float64() < individualProbability {
+			start, end := i, randomOtherColumn(rng, numQueens, i)
+			if start > end {
+				start, end = end, start
+			}
+			for ; start < end; start, end = start+1, end-1 {
+				ind.Swap(start, end)
+			}
+		}
+	}
+}
+
+// Mutate the individual by shuffling the queens of the columns selected with a certain probability among themselves
+func (ind *Individual) MutateScramble(rng *rand.Rand, individualProbability float64) {
+	selected := []int{}
+	for i := range ind.QueenPositions {
+		if rng.Float64() < individualProbability {
+			selected = append(selected, i)
+		}
+	}
+
+	// Fisher-Yates shuffle of the selected columns
+	for i := len(selected) - 1; i > 0; i-- {
+		j := rng.IntN(i + 1)
+		ind.Swap(selected[i], selected[j])
+	}
+}
+
+// Mutate the individual by moving each queen to a random column with a certain probability, shifting the queens in between
+func (ind *Individual) MutateInsertion(rng *rand.Rand, individualProbability float64) {
+	numQueens := len(ind.QueenPositions)
+	for i := 0; i < numQueens; i++ {
+		if rng.Float64() < individualProbability {
+			target := randomOtherColumn(rng, numQueens, i)
+			for col := i; col < target; col++ {
+				ind.Swap(col, col+1)
+			}
+			for col := i; col > target; col-- {
+				ind.Swap(col, col-1)
+			}
+		}
+	}
+}
+
+// Mutate the individual by swapping queens that are clashing with random columns
+// Each column triggers a swap with a certain probability, but the swapped queen is always one in conflict, so boards without clashes are never mutated
+func (ind *Individual) MutateConflictSwap(rng *rand.Rand, individualProbability float64) {
+	numQueens := len(ind.QueenPositions)
+	for i := 0; i < numQueens; i++ {
+		if rng.Float64() < individualProbability {
+			conflicted := ind.ConflictedColumns()
+			if len(conflicted) == 0 {
+				return
+			}
+			col := conflicted[rng.IntN(len(conflicted))]
+			ind.Swap(col, randomOtherColumn(rng, numQueens, col))
+		}
+	}
+}
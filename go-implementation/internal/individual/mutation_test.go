@@ -0,0 +1,57 @@
+package individual
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+var mutations = map[string]func(*Individual, *rand.Rand, float64){
+	"Swap":          (*Individual).Mutate,
+	"Inversion":     (*Individual).MutateInversion,
+	"Scramble":      (*Individual).MutateScramble,
+	"Insertion":     (*Individual).MutateInsertion,
+	"Conflict swap": (*Individual).MutateConflictSwap,
+}
+
+// Property test: every mutation keeps the individual a valid permutation and its cached fitness up to date
+func TestMutation_ValidPermutations(t *testing.T) {
+	for name, mutate := range mutations {
+		t.Run(name, func(t *testing.T) {
+			for seed := uint64(0); seed < 500; seed++ {
+				rng := rand.New(rand.NewPCG(seed, 1))
+				numQueens := 4 + rng.IntN(40)
+				ind := &Individual{QueenPositions: rng.Perm(numQueens)}
+				ind.Fitness()
+
+				mutate(ind, rng, rng.Float64())
+				if !isPermutation(ind.QueenPositions) {
+					t.Fatalf("mutation = %v, want a valid permutation", ind.QueenPositions)
+				}
+				if got, want := ind.Fitness(), bruteForceFitness(ind.QueenPositions); got != want {
+					t.Fatalf("Individual.Fitness() after mutation = %v, want %v", got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestIndividual_MutateConflictSwap(t *testing.T) {
+	// A solution has no queens in conflict, so it is never mutated
+	solution := []int{0, 6, 4, 7, 1, 3, 5, 2}
+	ind := &Individual{QueenPositions: slices.Clone(solution)}
+	ind.MutateConflictSwap(rand.New(rand.NewPCG(1, 1)), 1)
+	if !slices.Equal(ind.QueenPositions, solution) {
+		t.Errorf("Individual.MutateConflictSwap() of a solution = %v, want %v", ind.QueenPositions, solution)
+	}
+
+	// Every queen shares a diagonal with another one, except on solutions
+	ind = &Individual{QueenPositions: []int{0, 2, 1, 3}}
+	if got, want := ind.ConflictedColumns(), []int{0, 1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("Individual.ConflictedColumns() = %v, want %v", got, want)
+	}
+	ind = &Individual{QueenPositions: []int{1, 3, 0, 2}}
+	if got := ind.ConflictedColumns(); len(got) != 0 {
+		t.Errorf("Individual.ConflictedColumns() of a solution = %v, want none", got)
+	}
+}
@@ -0,0 +1,197 @@
+// Package metrics collects metrics of the runs of the genetic algorithm and writes them in the Prometheus text exposition format
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/dmarts05/genetic-n-queens/internal/population"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Upper bounds in seconds of the buckets of the histogram of run durations
+var DurationBuckets = []float64{0.01, 0.1, 0.5, 1, 5, 10, 30, 60, 300, 900, 3600}
+
+// Represents a run whose metrics are collected
+// Job: job the run belongs to when runs of several configurations are collected together, empty otherwise
+type runKey struct {
+	job string
+	run int
+}
+
+// Represents the state of a run whose metrics are collected
+type runState struct {
+	generation  int
+	bestFitness int
+	active      bool
+}
+
+// Represents the metrics of every run observed, safe for concurrent use
+// Counters only go up while the process lives, so rates such as generations per second are computed by Prometheus with rate()
+type Metrics struct {
+	mu          sync.Mutex
+	generations uint64
+	evaluations uint64
+	solutions   uint64
+	finished    map[result.StopReason]uint64
+	active      int
+	runs        map[runKey]*runState
+	buckets     []uint64 // Number of runs that lasted up to every bound of DurationBuckets
+	count       uint64
+	sum         float64
+}
+
+// Create metrics without any run
+func New() *Metrics {
+	return &Metrics{
+		finished: map[result.StopReason]uint64{},
+		runs:     map[runKey]*runState{},
+		buckets:  make([]uint64, len(DurationBuckets)),
+	}
+}
+
+// Create an observer collecting the metrics of the runs of a configuration
+// Runs of several configurations are told apart by job, which labels their series unless it is empty
+func (m *Metrics) Observer(job string) population.Observer {
+	return func(p population.Progress) {
+		m.observe(runKey{job, p.RunID}, p)
+	}
+}
+
+func (m *Metrics) observe(key runKey, p population.Progress) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// The first progress of a run is reported when it starts, so resumed runs don't count the generations before the checkpoint
+	r, ok := m.runs[key]
+	if !ok {
+		r = &runState{generation: p.Generation}
+		m.runs[key] = r
+	}
+	if !r.active && !p.Done() {
+		r.active = true
+		m.active++
+	}
+
+	// Several generations can be reported at once, e.g. the one that finds a solution along with the end of the run
+	if p.Generation > r.generation {
+		n := uint64(p.Generation - r.generation)
+		m.generations += n
+		m.evaluations += n * uint64(p.PopulationSize)
+		r.generation = p.Generation
+	}
+	r.bestFitness = p.Best.BestFitness
+	if !p.Done() {
+		return
+	}
+
+	if r.active {
+		r.active = false
+		m.active--
+	}
+	m.finished[p.StopReason]++
+	if p.StopReason == result.StopSolution {
+		m.solutions++
+	}
+	seconds := p.Elapsed.Seconds()
+	for i, bound := range DurationBuckets {
+		if seconds <= bound {
+			m.buckets[i]++
+		}
+	}
+	m.count++
+	m.sum += seconds
+}
+
+// Forget the series of the runs of a job, once its results aren't needed anymore
+func (m *Metrics) Forget(job string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key, r := range m.runs {
+		if key.job == job && !r.active {
+			delete(m.runs, key)
+		}
+	}
+}
+
+// Write the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteText(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := bufio.NewWriter(w)
+	writeHeader(b, "nqueens_generations_total", "counter", "Number of generations evaluated by every run.")
+	fmt.Fprintf(b, "nqueens_generations_total %v\n", m.generations)
+	writeHeader(b, "nqueens_evaluations_total", "counter", "Number of individuals evaluated by every run.")
+	fmt.Fprintf(b, "nqueens_evaluations_total %v\n", m.evaluations)
+	writeHeader(b, "nqueens_active_runs", "gauge", "Number of runs evolving.")
+	fmt.Fprintf(b, "nqueens_active_runs %v\n", m.active)
+	writeHeader(b, "nqueens_solutions_total", "counter", "Number of runs that found a solution.")
+	fmt.Fprintf(b, "nqueens_solutions_total %v\n", m.solutions)
+
+	writeHeader(b, "nqueens_runs_finished_total", "counter", "Number of runs that stopped, by the reason why they stopped.")
+	for _, reason := range []result.StopReason{result.StopSolution, result.StopMaxGenerations, result.StopTimeout, result.StopInterrupted, result.StopError} {
+		fmt.Fprintf(b, "nqueens_runs_finished_total{stop_reason=%q} %v\n", reason, m.finished[reason])
+	}
+
+	keys := make([]runKey, 0, len(m.runs))
+	for key := range m.runs {
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, func(a, b runKey) int {
+		if c := strings.Compare(a.job, b.job); c != 0 {
+			return c
+		}
+		return a.run - b.run
+	})
+	writeHeader(b, "nqueens_best_fitness", "gauge", "Best fitness found by a run so far.")
+	for _, key := range keys {
+		fmt.Fprintf(b, "nqueens_best_fitness%v %v\n", key.labels(), m.runs[key].bestFitness)
+	}
+	writeHeader(b, "nqueens_generation", "gauge", "Last generation evaluated by a run.")
+	for _, key := range keys {
+		fmt.Fprintf(b, "nqueens_generation%v %v\n", key.labels(), m.runs[key].generation)
+	}
+
+	writeHeader(b, "nqueens_run_duration_seconds", "histogram", "Duration of the runs that stopped.")
+	for i, bound := range DurationBuckets {
+		fmt.Fprintf(b, "nqueens_run_duration_seconds_bucket{le=%q} %v\n", formatFloat(bound), m.buckets[i])
+	}
+	fmt.Fprintf(b, "nqueens_run_duration_seconds_bucket{le=\"+Inf\"} %v\n", m.count)
+	fmt.Fprintf(b, "nqueens_run_duration_seconds_sum %v\n", formatFloat(m.sum))
+	fmt.Fprintf(b, "nqueens_run_duration_seconds_count %v\n", m.count)
+	return b.Flush()
+}
+
+// Serve the metrics in the Prometheus text exposition format
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteText(w)
+}
+
+func writeHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// Get the labels of the series of a run
+func (k runKey) labels() string {
+	if k.job == "" {
+		return fmt.Sprintf("{run=\"%v\"}", k.run)
+	}
+	return fmt.Sprintf("{job_id=\"%v\",run=\"%v\"}", escapeLabel(k.job), k.run)
+}
+
+// Escape a label value as the text exposition format requires
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
@@ -0,0 +1,131 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/population"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+func progress(run, generation, bestFitness int, elapsed time.Duration, reason result.StopReason) population.Progress {
+	return population.Progress{
+		RunID:          run,
+		Generation:     generation,
+		PopulationSize: 10,
+		Best:           result.GenerationResult{BestFitness: bestFitness},
+		Elapsed:        elapsed,
+		StopReason:     reason,
+	}
+}
+
+func TestMetrics_WriteText(t *testing.T) {
+	m := New()
+	observe := m.Observer("")
+
+	// Run 1 finds a solution at generation 3, reported along with its end, run 2 is still evolving
+	observe(progress(1, 0, 0, 0, ""))
+	observe(progress(2, 0, 0, 0, ""))
+	observe(progress(1, 1, 20, time.Second, ""))
+	observe(progress(2, 1, 21, time.Second, ""))
+	observe(progress(1, 2, 25, 2*time.Second, ""))
+	observe(progress(1, 3, 28, 3*time.Second, result.StopSolution))
+	observe(progress(2, 2, 22, 2*time.Second, ""))
+
+	var b strings.Builder
+	if err := m.WriteText(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP nqueens_generations_total Number of generations evaluated by every run.
+# TYPE nqueens_generations_total counter
+nqueens_generations_total 5
+# HELP nqueens_evaluations_total Number of individuals evaluated by every run.
+# TYPE nqueens_evaluations_total counter
+nqueens_evaluations_total 50
+# HELP nqueens_active_runs Number of runs evolving.
+# TYPE nqueens_active_runs gauge
+nqueens_active_runs 1
+# HELP nqueens_solutions_total Number of runs that found a solution.
+# TYPE nqueens_solutions_total counter
+nqueens_solutions_total 1
+# HELP nqueens_runs_finished_total Number of runs that stopped, by the reason why they stopped.
+# TYPE nqueens_runs_finished_total counter
+nqueens_runs_finished_total{stop_reason="solution"} 1
+nqueens_runs_finished_total{stop_reason="max_generations"} 0
+nqueens_runs_finished_total{stop_reason="timeout"} 0
+nqueens_runs_finished_total{stop_reason="interrupted"} 0
+nqueens_runs_finished_total{stop_reason="error"} 0
+# HELP nqueens_best_fitness Best fitness found by a run so far.
+# TYPE nqueens_best_fitness gauge
+nqueens_best_fitness{run="1"} 28
+nqueens_best_fitness{run="2"} 22
+# HELP nqueens_generation Last generation evaluated by a run.
+# TYPE nqueens_generation gauge
+nqueens_generation{run="1"} 3
+nqueens_generation{run="2"} 2
+# HELP nqueens_run_duration_seconds Duration of the runs that stopped.
+# TYPE nqueens_run_duration_seconds histogram
+nqueens_run_duration_seconds_bucket{le="0.01"} 0
+nqueens_run_duration_seconds_bucket{le="0.1"} 0
+nqueens_run_duration_seconds_bucket{le="0.5"} 0
+nqueens_run_duration_seconds_bucket{le="1"} 0
+nqueens_run_duration_seconds_bucket{le="5"} 1
+nqueens_run_duration_seconds_bucket{le="10"} 1
+nqueens_run_duration_seconds_bucket{le="30"} 1
+nqueens_run_duration_seconds_bucket{le="60"} 1
+nqueens_run_duration_seconds_bucket{le="300"} 1
+nqueens_run_duration_seconds_bucket{le="900"} 1
+nqueens_run_duration_seconds_bucket{le="3600"} 1
+nqueens_run_duration_seconds_bucket{le="+Inf"} 1
+nqueens_run_duration_seconds_sum 3
+nqueens_run_duration_seconds_count 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("WriteText() = \n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestMetrics_jobs(t *testing.T) {
+	m := New()
+
+	// A resumed run only counts the generations evolved since it was resumed
+	m.Observer("1")(progress(1, 49, 30, 0, ""))
+	m.Observer("1")(progress(1, 50, 30, time.Second, ""))
+	m.Observer("1")(progress(1, 51, 30, time.Second, result.StopInterrupted))
+	m.Observer(`a"b`)(progress(1, 0, 0, 0, ""))
+	m.Observer(`a"b`)(progress(1, 1, 10, time.Second, ""))
+
+	// A run whose operators fail stops before evaluating any generation
+	m.Observer("2")(progress(1, 0, 0, 0, result.StopError))
+
+	var b strings.Builder
+	m.WriteText(&b)
+	for _, want := range []string{
+		"nqueens_generations_total 3\n",
+		`nqueens_best_fitness{job_id="1",run="1"} 30` + "\n",
+		`nqueens_best_fitness{job_id="a\"b",run="1"} 10` + "\n",
+		`nqueens_runs_finished_total{stop_reason="interrupted"} 1` + "\n",
+		`nqueens_runs_finished_total{stop_reason="error"} 1` + "\n",
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("WriteText() = %v, want it to contain %q", b.String(), want)
+		}
+	}
+
+	// Only the finished runs of a forgotten job are removed
+	m.Forget("1")
+	m.Forget(`a"b`)
+	b.Reset()
+	m.WriteText(&b)
+	if strings.Contains(b.String(), `job_id="1"`) || !strings.Contains(b.String(), `job_id="a\"b"`) {
+		t.Errorf("WriteText() after Forget() = %v, want only the series of the running job", b.String())
+	}
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("ServeHTTP() content type = %v, want the text exposition format", ct)
+	}
+}
@@ -0,0 +1,199 @@
+package population
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Represents everything needed to continue a run exactly where it stopped
+// StopReason is only set for runs that have finished, runs that were interrupted or ran out of time can be resumed
+type RunState struct {
+	RunID           int                       `json:"run_id"`
+	Generation      int                       `json:"generation"`
+	Population      [][]int                   `json:"population"`
+	LearnedFitness  map[int]int               `json:"learned_fitness,omitempty"`
+	RNG             []byte                    `json:"rng"`
+	Best            result.GenerationResult   `json:"best"`
+	LastImprovement int                       `json:"last_improvement"`
+	History         []result.GenerationResult `json:"history,omitempty"`
+	StopReason      result.StopReason         `json:"stop_reason,omitempty"`
+}
+
+// Represents the state of every run of a configuration
+// Runs are indexed by their run ID minus one
+type Checkpoint struct {
+	Config   config.Config `json:"config"`
+	Interval int           `json:"interval"`
+	Runs     []RunState    `json:"runs"`
+}
+
+// Load a checkpoint from the specified path, checking that every run can be resumed
+func LoadCheckpoint(path string) (Checkpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Checkpoint{}, fmt.Errorf("load checkpoint: %w", err)
+	}
+
+	var cp Checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return Checkpoint{}, fmt.Errorf("load checkpoint: invalid checkpoint file: %w", err)
+	}
+	if len(cp.Runs) != cp.Config.NumRuns {
+		return Checkpoint{}, fmt.Errorf("load checkpoint: found %v runs, want %v", len(cp.Runs), cp.Config.NumRuns)
+	}
+	for i, state := range cp.Runs {
+		if state.RunID != i+1 {
+			return Checkpoint{}, fmt.Errorf("load checkpoint: run %v has not been saved", i+1)
+		}
+		if _, err := restoreEvolution(state, cp.Config); err != nil {
+			return Checkpoint{}, fmt.Errorf("load checkpoint: %w", err)
+		}
+	}
+	return cp, nil
+}
+
+// Saves the state of every run of a configuration to a file
+// A nil checkpointer doesn't save anything
+type Checkpointer struct {
+	path       string
+	onError    func(error) // Called with the error of every failed save, nil to ignore them
+	mu         sync.Mutex
+	checkpoint Checkpoint
+}
+
+// Create a checkpointer saving the runs of the configuration to the specified path every interval generations
+func NewCheckpointer(path string, cfg config.Config, interval int) *Checkpointer {
+	return NewCheckpointerFrom(path, Checkpoint{Config: cfg, Interval: interval, Runs: make([]RunState, cfg.NumRuns)})
+}
+
+// Create a checkpointer that keeps saving the runs of a loaded checkpoint to the specified path
+func NewCheckpointerFrom(path string, cp Checkpoint) *Checkpointer {
+	return &Checkpointer{path: path, checkpoint: cp}
+}
+
+// Call fn with the error of every failed save from the goroutine of the run, which keeps evolving
+func (c *Checkpointer) OnError(fn func(error)) {
+	c.onError = fn
+}
+
+// Check whether a run has to be saved after the given generation
+func (c *Checkpointer) due(generation int) bool {
+	return c != nil && c.checkpoint.Interval > 0 && generation%c.checkpoint.Interval == 0
+}
+
+// Update the state of a run and write every run to the file
+// The file is replaced atomically, so a crash while saving never leaves a broken checkpoint behind
+func (c *Checkpointer) save(state RunState) error {
+	if c == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkpoint.Runs[state.RunID-1] = state
+	data, err := json.Marshal(c.checkpoint)
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(data)
+	err = errors.Join(err, tmp.Close())
+	if err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), c.path); err != nil {
+		return fmt.Errorf("save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// Get the state of the evolution
+// Everything is copied since the evolution keeps modifying the population while the checkpoint is written
+func (e *evolution) state() RunState {
+	rngState, _ := e.src.MarshalBinary()
+	s := RunState{
+		RunID:           e.runID,
+		Generation:      e.generation,
+		Population:      make([][]int, len(e.pop)),
+		RNG:             rngState,
+		Best:            e.best,
+		LastImprovement: e.lastImprovement,
+		History:         slices.Clone(e.history),
+	}
+	for i, ind := range e.pop {
+		s.Population[i] = slices.Clone(ind.QueenPositions)
+		if fitness, ok := ind.LearnedFitness(); ok {
+			if s.LearnedFitness == nil {
+				s.LearnedFitness = map[int]int{}
+			}
+			s.LearnedFitness[i] = fitness
+		}
+	}
+	if e.stopReason == result.StopSolution || e.stopReason == result.StopMaxGenerations {
+		s.StopReason = e.stopReason
+	}
+	return s
+}
+
+// Restore an evolution from the state of a run
+func restoreEvolution(state RunState, cfg config.Config) (*evolution, error) {
+	if len(state.Population) == 0 {
+		return nil, fmt.Errorf("run %v has an empty population", state.RunID)
+	}
+
+	pop := make([]*individual.Individual, len(state.Population))
+	for i, queens := range state.Population {
+		if !isBoard(queens, cfg.NumQueens) {
+			return nil, fmt.Errorf("individual %v of run %v is not a board of %v queens", i, state.RunID, cfg.NumQueens)
+		}
+		pop[i] = &individual.Individual{QueenPositions: queens}
+		if fitness, ok := state.LearnedFitness[i]; ok {
+			pop[i].SetLearnedFitness(fitness)
+		}
+	}
+
+	src := &rand.PCG{}
+	if err := src.UnmarshalBinary(state.RNG); err != nil {
+		return nil, fmt.Errorf("run %v has an invalid random number generator state: %w", state.RunID, err)
+	}
+
+	e := newEvolutionFromSource(src, pop, cfg)
+	e.runID = state.RunID
+	e.generation = state.Generation
+	e.best = state.Best
+	e.lastImprovement = state.LastImprovement
+	e.history = state.History
+	e.stopReason = state.StopReason
+	return e, nil
+}
+
+// Check whether the queen positions are a permutation of the given number of queens
+func isBoard(queens []int, numQueens int) bool {
+	if len(queens) != numQueens {
+		return false
+	}
+	seen := make([]bool, numQueens)
+	for _, row := range queens {
+		if row < 0 || row >= numQueens || seen[row] {
+			return false
+		}
+		seen[row] = true
+	}
+	return true
+}
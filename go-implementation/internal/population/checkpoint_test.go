@@ -0,0 +1,136 @@
+package population
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+)
+
+func TestResume(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumQueens = 30
+	cfg.MaxGenerations = 60
+	cfg.RecordHistory = true
+	cfg.LocalSearchRate = 0.1
+	cfg.LocalSearchMode = config.Baldwinian
+	cfg.StagnationGenerations = 5
+
+	rng := util.NewRNG(1, 1)
+	want := Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+
+	// Stop halfway and continue from the saved state
+	rng = util.NewRNG(1, 1)
+	e := newEvolution(rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+	for e.generation < 25 && e.step(context.Background()) {
+	}
+	data, err := json.Marshal(e.state())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var state RunState
+	if err := json.Unmarshal(data, &state); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Resume(context.Background(), state, cfg, nil, nil)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Resume() = %v, want %v", got.Best, want.Best)
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumRuns = 2
+	cfg.MaxGenerations = 30
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	cp := NewCheckpointer(path, cfg, 10)
+
+	// An interrupted run is saved before evaluating its next generation, so it can be resumed
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var wg sync.WaitGroup
+	ch := make(chan result.RunResult, cfg.NumRuns)
+	for i := 0; i < cfg.NumRuns; i++ {
+		rng := util.NewRNG(cfg.Seed, uint64(i+1))
+		wg.Add(1)
+		go EvolveConcurrentWrapper(ctx, i+1, ch, &wg, rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg, cp, nil)
+	}
+	wg.Wait()
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint() error = %v", err)
+	}
+	if !reflect.DeepEqual(checkpoint.Config, cfg) || checkpoint.Interval != 10 {
+		t.Errorf("LoadCheckpoint() config = %v and interval %v, want %v and 10", checkpoint.Config, checkpoint.Interval, cfg)
+	}
+	for i, state := range checkpoint.Runs {
+		if state.RunID != i+1 || state.Generation != 1 || state.StopReason != "" {
+			t.Errorf("LoadCheckpoint() run %v = generation %v with reason %q, want generation 1 without reason", state.RunID, state.Generation, state.StopReason)
+		}
+	}
+
+	// A finished run isn't evolved any further
+	r, err := Resume(context.Background(), checkpoint.Runs[0], cfg, cp, nil)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	checkpoint, _ = LoadCheckpoint(path)
+	again, _ := Resume(context.Background(), checkpoint.Runs[0], cfg, nil, nil)
+	if r.Best.StopReason == "" || !reflect.DeepEqual(again, r) {
+		t.Errorf("Resume() of a finished run = %v, want %v", again.Best, r.Best)
+	}
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumRuns = 1
+	rng := util.NewRNG(1, 1)
+	e := newEvolution(rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+	e.runID = 1
+	valid := e.state()
+
+	invalidBoard := e.state()
+	invalidBoard.Population[0] = make([]int, cfg.NumQueens)
+	invalidRNG := e.state()
+	invalidRNG.RNG = []byte("invalid")
+
+	tests := []struct {
+		name    string
+		runs    []RunState
+		wantErr bool
+	}{
+		{"Valid checkpoint", []RunState{valid}, false},
+		{"Missing run", []RunState{}, true},
+		{"Unsaved run", []RunState{{}}, true},
+		{"Invalid board", []RunState{invalidBoard}, true},
+		{"Invalid random number generator", []RunState{invalidRNG}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "checkpoint.json")
+			data, _ := json.Marshal(Checkpoint{Config: cfg, Interval: 10, Runs: tt.runs})
+			if err := os.WriteFile(path, data, 0644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := LoadCheckpoint(path); (err != nil) != tt.wantErr {
+				t.Errorf("LoadCheckpoint() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := LoadCheckpoint(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadCheckpoint() of a missing file error = nil, want an error")
+	}
+}
@@ -0,0 +1,67 @@
+package population
+
+import (
+	"encoding/binary"
+	"math"
+
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+)
+
+// Represents how different the individuals of a population are
+// Hamming: Mean pairwise Hamming distance between the individuals normalized to [0, 1]
+// UniqueGenotypes: Number of different queen positions in the population
+// PositionalEntropy: Mean entropy of the rows of every column normalized to [0, 1]
+type diversityMetrics struct {
+	Hamming           float64
+	UniqueGenotypes   int
+	PositionalEntropy float64
+}
+
+// Measure the diversity of the population
+// Instead of comparing every pair of individuals, we count how many individuals have every row in every column, which is O(populationSize * numQueens)
+func measureDiversity(pop []*individual.Individual) diversityMetrics {
+	metrics := diversityMetrics{UniqueGenotypes: uniqueGenotypes(pop)}
+	if len(pop) < 2 {
+		return metrics
+	}
+
+	numQueens := len(pop[0].QueenPositions)
+	numPairs := len(pop) * (len(pop) - 1) / 2
+	counts := make([]int, numQueens)
+	differingPairs := 0
+	entropy := 0.0
+	for col := 0; col < numQueens; col++ {
+		clear(counts)
+		for _, ind := range pop {
+			counts[ind.QueenPositions[col]]++
+		}
+
+		samePairs := 0
+		for _, count := range counts {
+			samePairs += count * (count - 1) / 2
+			if count > 0 {
+				p := float64(count) / float64(len(pop))
+				entropy -= p * math.Log(p)
+			}
+		}
+		differingPairs += numPairs - samePairs
+	}
+
+	metrics.Hamming = float64(differingPairs) / float64(numPairs*numQueens)
+	metrics.PositionalEntropy = entropy / (float64(numQueens) * math.Log(float64(numQueens)))
+	return metrics
+}
+
+// Count the different queen positions in the population
+func uniqueGenotypes(pop []*individual.Individual) int {
+	seen := make(map[string]struct{}, len(pop))
+	key := []byte{}
+	for _, ind := range pop {
+		key = key[:0]
+		for _, row := range ind.QueenPositions {
+			key = binary.AppendUvarint(key, uint64(row))
+		}
+		seen[string(key)] = struct{}{}
+	}
+	return len(seen)
+}
@@ -0,0 +1,169 @@
+package population
+
+import (
+	"context"
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+)
+
+// Represents the channels connecting every pair of islands
+// Every island closes its done channel when it stops evolving, so the others don't wait for its migrants anymore
+type archipelago struct {
+	cfg   config.Config
+	links [][]chan []*individual.Individual
+	done  []chan struct{}
+}
+
+func newArchipelago(cfg config.Config) *archipelago {
+	numIslands := cfg.NumRuns
+	a := &archipelago{
+		cfg:   cfg,
+		links: make([][]chan []*individual.Individual, numIslands),
+		done:  make([]chan struct{}, numIslands),
+	}
+	for i := range a.links {
+		a.links[i] = make([]chan []*individual.Individual, numIslands)
+		for j := range a.links[i] {
+			a.links[i][j] = make(chan []*individual.Individual, 1)
+		}
+		a.done[i] = make(chan struct{})
+	}
+	return a
+}
+
+// Get the islands every island sends its migrants to in the given epoch
+// The random topology is derived from the seed and the epoch, so every island computes the same one
+func (a *archipelago) neighbours(epoch int) [][]int {
+	numIslands := len(a.links)
+	neighbours := make([][]int, numIslands)
+	if numIslands < 2 {
+		return neighbours
+	}
+
+	switch a.cfg.MigrationTopology {
+	case config.RingTopology:
+		for i := range neighbours {
+			neighbours[i] = []int{(i + 1) % numIslands}
+		}
+	case config.FullTopology:
+		for i := range neighbours {
+			for j := 0; j < numIslands; j++ {
+				if j != i {
+					neighbours[i] = append(neighbours[i], j)
+				}
+			}
+		}
+	case config.RandomTopology:
+		rng := util.NewRNG(a.cfg.Seed, math.MaxUint64-uint64(epoch))
+		for i := range neighbours {
+			j := rng.IntN(numIslands - 1)
+			if j >= i {
+				j++
+			}
+			neighbours[i] = []int{j}
+		}
+	}
+	return neighbours
+}
+
+// Send the best individuals of an island to its neighbours and replace its worst individuals with the best ones received
+func (a *archipelago) migrate(island, epoch int, pop []*individual.Individual) {
+	neighbours := a.neighbours(epoch)
+
+	// Send clones of the best individuals, skipping neighbours that have already stopped
+	emigrants := bestIndividuals(pop, a.cfg.MigrationSize)
+	for _, neighbour := range neighbours[island] {
+		migrants := make([]*individual.Individual, len(emigrants))
+		for i, ind := range emigrants {
+			migrants[i] = ind.Clone()
+		}
+		select {
+		case a.links[island][neighbour] <- migrants:
+		case <-a.done[neighbour]:
+		}
+	}
+
+	// Receive the migrants of every island sending to this one in index order, so the result doesn't depend on scheduling
+	immigrants := []*individual.Individual{}
+	for sender, senderNeighbours := range neighbours {
+		if !slices.Contains(senderNeighbours, island) {
+			continue
+		}
+		if migrants, ok := a.receive(sender, island); ok {
+			immigrants = append(immigrants, migrants...)
+		}
+	}
+	if len(immigrants) == 0 {
+		return
+	}
+
+	// Replace the worst individuals with the best immigrants
+	immigrants = bestIndividuals(immigrants, a.cfg.MigrationSize)
+	worst := worstIndices(pop, len(immigrants))
+	for i, idx := range worst {
+		pop[idx] = immigrants[i]
+	}
+}
+
+// Wait for the migrants of the sender, unless it has already stopped without sending them
+func (a *archipelago) receive(sender, receiver int) ([]*individual.Individual, bool) {
+	select {
+	case migrants := <-a.links[sender][receiver]:
+		return migrants, true
+	case <-a.done[sender]:
+		// The sender may have sent its last migrants right before stopping
+		select {
+		case migrants := <-a.links[sender][receiver]:
+			return migrants, true
+		default:
+			return nil, false
+		}
+	}
+}
+
+// Evolve every island concurrently with its own population, exchanging the best individuals with its neighbours every few generations
+// Every island is a run of the configuration and the results are returned in island order
+// The progress of every island is reported to the observer, unless it is nil
+func EvolveIslands(ctx context.Context, cfg config.Config, observe Observer) []result.RunResult {
+	ctx, cancel := withMaxDuration(ctx, cfg)
+	defer cancel()
+
+	a := newArchipelago(cfg)
+	results := make([]result.RunResult, cfg.NumRuns)
+	var wg sync.WaitGroup
+	for i := 0; i < cfg.NumRuns; i++ {
+		wg.Add(1)
+		go func(island int) {
+			defer wg.Done()
+			defer close(a.done[island])
+
+			rng := util.NewRNG(cfg.Seed, uint64(island+1))
+			e := newEvolution(rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+			e.runID = island + 1
+			e.observer = observe
+			e.started = time.Now()
+			e.notify()
+			for e.step(ctx) {
+				e.notify()
+				if e.generation%cfg.MigrationInterval == 0 {
+					a.migrate(island, e.generation/cfg.MigrationInterval, e.pop)
+				}
+			}
+			e.notify()
+
+			r := e.result()
+			setRunID(&r, island+1)
+			results[island] = r
+		}(i)
+	}
+	wg.Wait()
+
+	return results
+}
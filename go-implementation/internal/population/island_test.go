@@ -0,0 +1,64 @@
+package population
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+)
+
+func TestEvolveIslandsReproducible(t *testing.T) {
+	for _, topology := range []config.TopologyType{config.RingTopology, config.FullTopology, config.RandomTopology} {
+		t.Run(string(topology), func(t *testing.T) {
+			cfg := testConfig()
+			cfg.NumRuns = 4
+			cfg.Seed = 42
+			cfg.IslandMode = true
+			cfg.MigrationInterval = 5
+			cfg.MigrationSize = 2
+			cfg.MigrationTopology = topology
+
+			first := EvolveIslands(context.Background(), cfg, nil)
+			second := EvolveIslands(context.Background(), cfg, nil)
+			if len(first) != cfg.NumRuns {
+				t.Fatalf("EvolveIslands() returned %v results, want %v", len(first), cfg.NumRuns)
+			}
+			for i, r := range first {
+				if r.Best.RunID != i+1 {
+					t.Errorf("EvolveIslands() result %v has run ID %v, want %v", i, r.Best.RunID, i+1)
+				}
+			}
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("EvolveIslands() with the same seed = %v, want %v", second, first)
+			}
+		})
+	}
+}
+
+func TestArchipelago_migrate(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumRuns = 2
+	cfg.MigrationSize = 1
+	cfg.MigrationTopology = config.RingTopology
+	a := newArchipelago(cfg)
+
+	solution := &individual.Individual{QueenPositions: []int{0, 6, 4, 7, 1, 3, 5, 2}}
+	bad := &individual.Individual{QueenPositions: []int{0, 1, 2, 3, 4, 5, 6, 7}}
+	average := &individual.Individual{QueenPositions: []int{5, 2, 4, 6, 0, 3, 7, 1}}
+
+	// The first island sends its solution to the second one, which replaces its worst individual with it
+	a.links[1][0] <- []*individual.Individual{bad.Clone()}
+	pop1 := []*individual.Individual{solution, bad}
+	a.migrate(0, 1, pop1)
+
+	pop2 := []*individual.Individual{average, bad}
+	a.migrate(1, 1, pop2)
+	if pop2[0] != average || !reflect.DeepEqual(pop2[1].QueenPositions, solution.QueenPositions) {
+		t.Errorf("migrate() = %v, want the worst individual replaced by the solution", pop2)
+	}
+	if pop2[1] == solution {
+		t.Errorf("migrate() shared the individual between islands, want a clone")
+	}
+}
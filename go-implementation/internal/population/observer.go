@@ -0,0 +1,52 @@
+package population
+
+import (
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Represents the progress of a run when it starts, after evaluating a generation, or once it has stopped
+// Generation: last evaluated generation, 0 when a new run starts
+// PopulationSize: number of individuals evaluated every generation
+// Current: statistics of the last evaluated generation
+// Best: best generation so far
+// Elapsed: time since the run started, or since it was resumed
+// StopReason: why the run stopped, only set on the last progress of the run
+type Progress struct {
+	RunID          int
+	Generation     int
+	MaxGenerations int
+	PopulationSize int
+	Current        result.GenerationResult
+	Best           result.GenerationResult
+	Elapsed        time.Duration
+	StopReason     result.StopReason
+}
+
+// Check whether the run has stopped
+func (p Progress) Done() bool {
+	return p.StopReason != ""
+}
+
+// Called with the progress of a run when it starts, after every generation and once more when it stops
+// Runs evolve concurrently, so an observer shared by several runs must be safe for concurrent use
+// It is called from the goroutine of the run, so it should return quickly
+type Observer func(Progress)
+
+// Report the progress of the evolution to its observer, if any
+func (e *evolution) notify() {
+	if e.observer == nil {
+		return
+	}
+	e.observer(Progress{
+		RunID:          e.runID,
+		Generation:     e.generation,
+		MaxGenerations: e.cfg.MaxGenerations,
+		PopulationSize: e.cfg.PopulationSize,
+		Current:        e.current,
+		Best:           e.best,
+		Elapsed:        time.Since(e.started),
+		StopReason:     e.stopReason,
+	})
+}
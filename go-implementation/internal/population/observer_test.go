@@ -0,0 +1,50 @@
+package population
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+)
+
+func TestEvolveObserved(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumQueens = 30
+	cfg.MaxGenerations = 20
+
+	progress := []Progress{}
+	rng := util.NewRNG(5, 1)
+	r := EvolveObserved(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg, func(p Progress) {
+		progress = append(progress, p)
+	})
+
+	// 30 queens aren't solved in 20 generations with this seed, so the run reports every generation:
+	// one progress when it starts, one for each of the first 19 and a last one for the 20th once the run stops
+	if r.Best.StopReason != result.StopMaxGenerations {
+		t.Fatalf("EvolveObserved() stop reason = %v, want %v", r.Best.StopReason, result.StopMaxGenerations)
+	}
+	if len(progress) != cfg.MaxGenerations+1 {
+		t.Fatalf("EvolveObserved() reported %v progresses, want %v", len(progress), cfg.MaxGenerations+1)
+	}
+	for i, p := range progress[:len(progress)-1] {
+		if p.Generation != i || p.Current.Generation != i || p.MaxGenerations != cfg.MaxGenerations || p.Done() {
+			t.Errorf("EvolveObserved() progress %v = %+v, want generation %v of a running run", i, p, i)
+		}
+		if i > 0 && p.Best.BestFitness < progress[i-1].Best.BestFitness {
+			t.Errorf("EvolveObserved() best fitness went from %v to %v", progress[i-1].Best.BestFitness, p.Best.BestFitness)
+		}
+	}
+
+	last := progress[len(progress)-1]
+	if !last.Done() || last.Generation != cfg.MaxGenerations || last.StopReason != r.Best.StopReason || !reflect.DeepEqual(last.Best.BestQueenPositions, r.Best.BestQueenPositions) {
+		t.Errorf("EvolveObserved() last progress = %+v, want the stopped run %+v", last, r.Best)
+	}
+
+	// Observing a run doesn't change it
+	rng = util.NewRNG(5, 1)
+	if want := Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg); !reflect.DeepEqual(r, want) {
+		t.Errorf("EvolveObserved() = %v, want %v", r.Best, want.Best)
+	}
+}
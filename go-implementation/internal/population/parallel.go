@@ -0,0 +1,60 @@
+package population
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+)
+
+// Call fn for every index from 0 to n-1 using up to the given number of goroutines
+// Indices are handed out one at a time, so slow items such as local search don't leave the other workers idle
+// A panic of fn is raised again in the calling goroutine once every worker has stopped, so the caller can recover from it
+func parallelFor(workers, n int, fn func(i int)) {
+	if workers <= 1 || n <= 1 {
+		for i := 0; i < n; i++ {
+			fn(i)
+		}
+		return
+	}
+
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	var once sync.Once
+	var panicked any
+	for w := 0; w < min(workers, n); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if v := recover(); v != nil {
+					once.Do(func() { panicked = v })
+				}
+			}()
+			for i := int(next.Add(1)) - 1; i < n; i = int(next.Add(1)) - 1 {
+				fn(i)
+			}
+		}()
+	}
+	wg.Wait()
+	if panicked != nil {
+		panic(panicked)
+	}
+}
+
+// Compute and cache the fitness of every individual of the population concurrently
+// Individuals appearing more than once are only evaluated once, since evaluating the same individual concurrently is not safe
+func evaluateFitness(pop []*individual.Individual, workers int) {
+	seen := make(map[*individual.Individual]struct{}, len(pop))
+	unique := make([]*individual.Individual, 0, len(pop))
+	for _, ind := range pop {
+		if _, ok := seen[ind]; !ok {
+			seen[ind] = struct{}{}
+			unique = append(unique, ind)
+		}
+	}
+
+	parallelFor(workers, len(unique), func(i int) {
+		unique[i].Fitness()
+	})
+}
@@ -1,145 +1,459 @@
 package population
 
 import (
+	"cmp"
+	"context"
+	"errors"
 	"fmt"
-	"log"
+	"math"
 	"math/rand/v2"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/dmarts05/genetic-n-queens/internal/config"
 	"github.com/dmarts05/genetic-n-queens/internal/individual"
 	"github.com/dmarts05/genetic-n-queens/internal/result"
 	"github.com/dmarts05/genetic-n-queens/internal/selection"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
 )
 
-const tournamentSize = 3
-
 // Generate a random individual
-func generateRandomIndividual(numQueens int) *individual.Individual {
-	return &individual.Individual{QueenPositions: rand.Perm(numQueens)}
+func generateRandomIndividual(rng *rand.Rand, numQueens int) *individual.Individual {
+	return &individual.Individual{QueenPositions: rng.Perm(numQueens)}
 }
 
 // Generate a population of random individuals with the given number of queens and population size
-func Generate(numQueens, populationSize int) []*individual.Individual {
+func Generate(rng *rand.Rand, numQueens, populationSize int) []*individual.Individual {
 	population := make([]*individual.Individual, populationSize)
 
 	for i := 0; i < populationSize; i++ {
-		population[i] = generateRandomIndividual(numQueens)
+		population[i] = generateRandomIndividual(rng, numQueens)
 	}
 
 	return population
 }
 
 // Wrapper for Evolve function to be used with goroutines
-func EvolveConcurrentWrapper(workerID int, ch chan<- result.GenerationResult, wg *sync.WaitGroup, pop []*individual.Individual, selectionMethod config.SelectionMethodType, maxGenerations int, mutationRate float64, crossoverRate float64, elitism bool, bestPossibleFitness int) {
-	var r result.GenerationResult
+// The state of the run is saved with the checkpointer and its progress reported to the observer, unless they are nil
+func EvolveConcurrentWrapper(ctx context.Context, workerID int, ch chan<- result.RunResult, wg *sync.WaitGroup, rng *rand.Rand, pop []*individual.Individual, cfg config.Config, cp *Checkpointer, observe Observer) {
+	runConcurrently(workerID, ch, wg, func() result.RunResult {
+		return EvolveRun(ctx, workerID, rng, pop, cfg, cp, observe)
+	})
+}
 
-	defer func() {
-		fmt.Println("------------------------------------------------------------")
-		if r.BestFitness == bestPossibleFitness {
-			fmt.Println("Worker", workerID, "has found one of the optimal solutions:", r.BestQueenPositions)
-		} else {
-			fmt.Println("Worker", workerID, "has finished with a suboptimal solution:", r.BestQueenPositions, "with fitness", r.BestFitness)
+// Evolve the population as the run with the given ID, which is set on its results and its progress
+// The state of the run is saved with the checkpointer and its progress reported to the observer, unless they are nil
+func EvolveRun(ctx context.Context, runID int, rng *rand.Rand, pop []*individual.Individual, cfg config.Config, cp *Checkpointer, observe Observer) result.RunResult {
+	e := newEvolution(rng, pop, cfg)
+	e.runID = runID
+	e.observer = observe
+	r := e.run(ctx, cp)
+	setRunID(&r, runID)
+	return r
+}
+
+// Wrapper for Resume function to be used with goroutines
+// A run that can't be resumed is sent with the error
+func ResumeConcurrentWrapper(ctx context.Context, ch chan<- result.RunResult, wg *sync.WaitGroup, state RunState, cfg config.Config, cp *Checkpointer, observe Observer) {
+	runConcurrently(state.RunID, ch, wg, func() result.RunResult {
+		r, err := Resume(ctx, state, cfg, cp, observe)
+		if err != nil {
+			return result.RunResult{Best: result.GenerationResult{StopReason: result.StopError}, Err: err}
 		}
-		fmt.Println("------------------------------------------------------------")
-		wg.Done()
-	}()
+		return r
+	})
+}
+
+// Run a worker, sending its result to the channel
+func runConcurrently(workerID int, ch chan<- result.RunResult, wg *sync.WaitGroup, run func() result.RunResult) {
+	defer wg.Done()
 
-	r = Evolve(pop, selectionMethod, maxGenerations, mutationRate, crossoverRate, elitism, bestPossibleFitness)
+	r := run()
+	setRunID(&r, workerID)
 	ch <- r
 }
 
+// Set the run ID of the best generation and the history of a run
+func setRunID(r *result.RunResult, runID int) {
+	r.Best.RunID = runID
+	for i := range r.History {
+		r.History[i].RunID = runID
+	}
+}
+
 // Evolve the population by applying the selection, crossover and mutation methods and return the best generation
-func Evolve(pop []*individual.Individual, selectionMethod config.SelectionMethodType, maxGenerations int, mutationRate float64, crossoverRate float64, elitism bool, bestPossibleFitness int) result.GenerationResult {
-	results := []result.GenerationResult{}
-
-	for generation := 1; generation <= maxGenerations; generation++ {
-		// Evaluate fitness
-		bestIndividual := pop[0]
-		for _, ind := range pop {
-			fitness := ind.Fitness()
-			if fitness > bestIndividual.Fitness() {
-				bestIndividual = ind
-			}
+// Every generation is also returned when history recording is enabled in the configuration
+// Evolution stops early when the context is cancelled or the maximum duration of the configuration is exceeded
+func Evolve(ctx context.Context, rng *rand.Rand, pop []*individual.Individual, cfg config.Config) result.RunResult {
+	return EvolveObserved(ctx, rng, pop, cfg, nil)
+}
+
+// Evolve the population as Evolve does, reporting the progress of the run to the observer after every generation
+func EvolveObserved(ctx context.Context, rng *rand.Rand, pop []*individual.Individual, cfg config.Config, observe Observer) result.RunResult {
+	e := newEvolution(rng, pop, cfg)
+	e.observer = observe
+	return e.run(ctx, nil)
+}
+
+// Continue a run from its state saved in a checkpoint, reporting its progress to the observer unless it is nil
+// The maximum duration of the configuration starts counting again from the moment the run is resumed
+func Resume(ctx context.Context, state RunState, cfg config.Config, cp *Checkpointer, observe Observer) (result.RunResult, error) {
+	e, err := restoreEvolution(state, cfg)
+	if err != nil {
+		return result.RunResult{}, fmt.Errorf("resume: %w", err)
+	}
+	e.observer = observe
+	return e.run(ctx, cp), nil
+}
+
+// Limit the context to the maximum duration of the configuration, if any
+func withMaxDuration(ctx context.Context, cfg config.Config) (context.Context, context.CancelFunc) {
+	if cfg.MaxDuration > 0 {
+		return context.WithTimeout(ctx, time.Duration(cfg.MaxDuration))
+	}
+	return context.WithCancel(ctx)
+}
+
+// Represents a population evolving generation by generation
+type evolution struct {
+	cfg                 config.Config
+	src                 *rand.PCG // Source of rng, kept to save its state in checkpoints
+	rng                 *rand.Rand
+	ops                 operator.Set
+	runID               int
+	pop                 []*individual.Individual
+	generation          int
+	bestPossibleFitness int
+	current             result.GenerationResult   // Last evaluated generation
+	best                result.GenerationResult   // Best generation so far
+	history             []result.GenerationResult // Every generation, only kept when recording history
+	stopReason          result.StopReason
+	err                 error // Why the evolution stopped when it can't go on, its stop reason is then StopError
+	lastImprovement     int   // Generation in which the best fitness last improved or the population was restarted
+	observer            Observer
+	started             time.Time // When the evolution started running, or was resumed
+}
+
+// Create an evolution with its own random number generator seeded from the given one
+func newEvolution(rng *rand.Rand, pop []*individual.Individual, cfg config.Config) *evolution {
+	return newEvolutionFromSource(rand.NewPCG(rng.Uint64(), rng.Uint64()), pop, cfg)
+}
+
+// An evolution whose operators can't be created from the configuration stops with the error before its first generation
+func newEvolutionFromSource(src *rand.PCG, pop []*individual.Individual, cfg config.Config) *evolution {
+	ops, err := operator.FromConfig(cfg)
+	return &evolution{
+		cfg:                 cfg,
+		src:                 src,
+		rng:                 rand.New(src),
+		ops:                 ops,
+		pop:                 pop,
+		bestPossibleFitness: cfg.BestPossibleFitness(),
+		err:                 err,
+	}
+}
+
+// Evolve until the evolution has to stop and return its result
+// The state is saved with the checkpointer when starting, every checkpoint interval and when stopping
+func (e *evolution) run(ctx context.Context, cp *Checkpointer) result.RunResult {
+	ctx, cancel := withMaxDuration(ctx, e.cfg)
+	defer cancel()
+
+	// Runs resumed from a checkpoint may have already finished
+	e.started = time.Now()
+	if e.stopReason != "" {
+		e.notify()
+		return e.result()
+	}
+
+	// Report the start of the run before evaluating its first generation, or the first one after the checkpoint
+	e.notify()
+	e.checkpoint(cp)
+	for e.step(ctx) {
+		e.notify()
+		if cp.due(e.generation) {
+			e.checkpoint(cp)
 		}
+	}
+	e.checkpoint(cp)
+	e.notify()
+	return e.result()
+}
+
+// Save the state of the evolution, a failed checkpoint doesn't stop the evolution and is reported to the checkpointer
+func (e *evolution) checkpoint(cp *Checkpointer) {
+	if err := cp.save(e.state()); err != nil && cp.onError != nil {
+		cp.onError(err)
+	}
+}
 
-		meanFitness := 0.0
-		for _, ind := range pop {
-			meanFitness += float64(ind.Fitness())
+// Evaluate the current generation and breed the next one, returning false once the evolution has to stop
+// A generation that panics stops the evolution with an error, so a failing run doesn't crash the whole program
+func (e *evolution) step(ctx context.Context) (ok bool) {
+	defer func() {
+		if v := recover(); v != nil {
+			e.err = fmt.Errorf("run %v panicked at generation %v: %v", e.runID, e.generation, v)
+			e.stopReason = result.StopError
+			ok = false
 		}
-		meanFitness = meanFitness / float64(len(pop))
-
-		bestQueenPositions := make([]int, len(bestIndividual.QueenPositions))
-		copy(bestQueenPositions, bestIndividual.QueenPositions)
-		bestFitness := bestIndividual.Fitness()
-		results = append(results, result.GenerationResult{
-			Generation:         generation,
-			BestQueenPositions: bestQueenPositions,
-			BestFitness:        bestFitness,
-			MeanFitness:        meanFitness,
-			IsSolution:         bestFitness == bestPossibleFitness,
-		})
+	}()
+
+	if e.err != nil {
+		e.stopReason = result.StopError
+		return false
+	}
+
+	// Check if we have been cancelled or ran out of time, the first generation is always evaluated
+	// This happens before evaluating so an interrupted evolution can be resumed from its state
+	if err := ctx.Err(); err != nil && e.generation > 0 {
+		e.stopReason = result.StopInterrupted
+		if errors.Is(err, context.DeadlineExceeded) {
+			e.stopReason = result.StopTimeout
+		}
+		return false
+	}
+
+	e.generation++
+
+	// Evaluate fitness
+	evaluateFitness(e.pop, e.cfg.Workers)
+	r, bestIndividual := evaluate(e.pop, e.generation, e.bestPossibleFitness)
+	e.record(r)
+
+	// Check if we have reached the best possible fitness
+	if bestIndividual.GenotypeFitness() == e.bestPossibleFitness {
+		e.stopReason = result.StopSolution
+		return false
+	}
+
+	// Check if this was the last generation
+	if e.generation >= e.cfg.MaxGenerations {
+		e.stopReason = result.StopMaxGenerations
+		return false
+	}
+
+	// Restart part of the population if it has stagnated
+	if e.cfg.DetectsStagnation() && e.stagnated(r) {
+		e.restart()
+	}
+
+	pop, err := e.breed()
+	if err != nil {
+		e.err = fmt.Errorf("run %v, generation %v: %w", e.runID, e.generation, err)
+		e.stopReason = result.StopError
+		return false
+	}
+	e.pop = pop
+	return true
+}
+
+// Keep the result of the current generation if it is the best so far or history is being recorded
+func (e *evolution) record(r result.GenerationResult) {
+	e.current = r
+	if e.generation == 1 || r.BestFitness > e.best.BestFitness {
+		e.best = r
+		e.lastImprovement = e.generation
+	}
+	if e.cfg.RecordHistory {
+		e.history = append(e.history, r)
+	}
+}
 
-		// Check if we have reached the best possible fitness
-		if bestIndividual.Fitness() == bestPossibleFitness {
-			break
+// Check whether the best fitness hasn't improved for too long or the population has lost its diversity
+func (e *evolution) stagnated(r result.GenerationResult) bool {
+	stalled := e.cfg.StagnationGenerations > 0 && e.generation-e.lastImprovement >= e.cfg.StagnationGenerations
+	converged := e.cfg.DiversityThreshold > 0 && r.Diversity < e.cfg.DiversityThreshold
+	return stalled || converged
+}
+
+// Replace the worst individuals with random ones or heavily mutated copies of themselves
+func (e *evolution) restart() {
+	numRestarted := int(math.Ceil(e.cfg.RestartFraction * float64(len(e.pop))))
+	for _, idx := range worstIndices(e.pop, numRestarted) {
+		switch e.cfg.StagnationAction {
+		case config.Reseed:
+			e.pop[idx] = generateRandomIndividual(e.rng, e.cfg.NumQueens)
+		case config.Hypermutation:
+			// Mutate a copy since the same individual may appear more than once in the population
+			ind := e.pop[idx].Clone()
+			ind.Mutate(e.rng, e.cfg.HypermutationRate)
+			e.pop[idx] = ind
 		}
+	}
+	e.lastImprovement = e.generation
+
+	// Flag the current generation as restarted
+	if e.best.Generation == e.generation {
+		e.best.Restarted = true
+	}
+	if e.cfg.RecordHistory {
+		e.history[len(e.history)-1].Restarted = true
+	}
+}
+
+// Create the next generation by applying the selection, crossover and mutation methods
+// The errors of the operators are returned joined, as pairs of parents are bred concurrently
+func (e *evolution) breed() ([]*individual.Individual, error) {
+	// Select parents
+	parents := e.ops.Selector.Select(e.rng, e.pop)
+
+	// Create the offspring of every pair of parents concurrently
+	// Every pair gets its own random number generator seeded in order, so the offspring don't depend on the number of workers
+	seeds := make([]uint64, len(parents)/2)
+	for i := range seeds {
+		seeds[i] = e.rng.Uint64()
+	}
+	newPop := make([]*individual.Individual, 2*len(seeds))
+	errs := make([]error, len(seeds))
+	parallelFor(e.cfg.Workers, len(seeds), func(i int) {
+		newPop[2*i], newPop[2*i+1], errs[i] = e.offspring(util.NewRNG(seeds[i], 0), parents[2*i], parents[2*i+1])
+	})
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
 
-		// Select parents
-		var parents []*individual.Individual
-		switch selectionMethod {
-		case config.Roulette:
-			parents = selection.SelectByRoulette(pop)
-		case config.Tournament:
-			parents = selection.SelectByTournament(pop, tournamentSize)
+	// Perform elitist reduction if enabled
+	if e.cfg.Elitism {
+		extendedPop := append(e.pop, newPop...)
+		elites := selection.SelectByElitism(extendedPop, len(e.pop))
+		newPop = elites
+	}
+
+	// Improve the best individuals with local search if enabled
+	if e.cfg.LocalSearchRate > 0 && e.cfg.LocalSearchTarget == config.Elites {
+		numElites := int(math.Ceil(e.cfg.LocalSearchRate * float64(len(newPop))))
+		elites := bestIndividuals(newPop, numElites)
+		seeds := make([]uint64, len(elites))
+		for i := range seeds {
+			seeds[i] = e.rng.Uint64()
 		}
+		parallelFor(e.cfg.Workers, len(elites), func(i int) {
+			e.improve(util.NewRNG(seeds[i], 0), elites[i])
+		})
+	}
 
-		// Crossover
-		newPop := []*individual.Individual{}
-		for i := 0; i < len(parents); i += 2 {
-			doCrossover := rand.Float64() < crossoverRate
-			if i+1 < len(parents) {
-				parent1 := parents[i]
-				parent2 := parents[i+1]
-				if doCrossover {
-					child1, child2, err := parent1.Crossover(parent2)
-					if err != nil {
-						log.Fatal(err)
-					}
-					newPop = append(newPop, child1, child2)
-				} else {
-					newPop = append(newPop, parent1, parent2)
-				}
-			}
+	return newPop, nil
+}
+
+// Create two children by applying the crossover and mutation methods to a pair of parents
+// Parents that aren't crossed over are copied, so the children can be mutated without changing the current population
+func (e *evolution) offspring(rng *rand.Rand, parent1, parent2 *individual.Individual) (*individual.Individual, *individual.Individual, error) {
+	var child1, child2 *individual.Individual
+	if rng.Float64() < e.cfg.CrossOverRate {
+		var err error
+		child1, child2, err = e.ops.Crossoverer.Crossover(rng, parent1, parent2)
+		if err != nil {
+			return nil, nil, err
 		}
+	} else {
+		child1, child2 = parent1.Clone(), parent2.Clone()
+	}
 
+	for _, child := range []*individual.Individual{child1, child2} {
 		// Mutate
-		for _, ind := range newPop {
-			doMutate := rand.Float64() < mutationRate
-			if doMutate {
-				// Since the mutation rate is per individual, we need to adjust it based on the number of queens
-				numQueens := len(ind.QueenPositions)
-				ind.Mutate(2.0 / float64(numQueens))
-			}
+		if rng.Float64() < e.cfg.MutationRate {
+			e.ops.Mutator.Mutate(rng, child)
 		}
 
-		// Perform elitist reduction if enabled
-		if elitism {
-			extendedPop := append(pop, newPop...)
-			elites := selection.SelectByElitism(extendedPop, len(pop))
-			newPop = elites
+		// Improve the offspring with local search if enabled
+		if e.cfg.LocalSearchRate > 0 && e.cfg.LocalSearchTarget == config.Offspring && rng.Float64() < e.cfg.LocalSearchRate {
+			e.improve(rng, child)
 		}
 
-		pop = newPop
+		// Evaluate fitness while we are still in the worker
+		child.Fitness()
 	}
 
-	// Get best result
-	best_result := results[0]
-	for _, result := range results {
-		if result.BestFitness > best_result.BestFitness {
-			best_result = result
+	return child1, child2, nil
+}
+
+// Apply local search to an individual
+// With Baldwinian learning only the fitness is improved, unless a solution is found, which is written back so it isn't lost
+func (e *evolution) improve(rng *rand.Rand, ind *individual.Individual) {
+	if e.cfg.LocalSearchMode == config.Lamarckian {
+		ind.MinConflicts(rng, e.cfg.LocalSearchDepth)
+		return
+	}
+
+	learner := ind.Clone()
+	learner.MinConflicts(rng, e.cfg.LocalSearchDepth)
+	if learner.GenotypeFitness() == e.bestPossibleFitness {
+		copy(ind.QueenPositions, learner.QueenPositions)
+		ind.Invalidate()
+		return
+	}
+	ind.SetLearnedFitness(learner.GenotypeFitness())
+}
+
+// Get the result of the run with its best generation
+func (e *evolution) result() result.RunResult {
+	best := e.best
+	best.StopReason = e.stopReason
+	return result.RunResult{Best: best, History: e.history, Err: e.err}
+}
+
+// Calculate the statistics of a generation and return them along with its best individual
+// The statistics use the fitness of the queens of every individual, so learned fitness never makes a board look better than it is
+func evaluate(pop []*individual.Individual, generation, bestPossibleFitness int) (result.GenerationResult, *individual.Individual) {
+	bestIndividual := pop[0]
+	worstFitness := pop[0].GenotypeFitness()
+	meanFitness := 0.0
+	for _, ind := range pop {
+		fitness := ind.GenotypeFitness()
+		if fitness > bestIndividual.GenotypeFitness() {
+			bestIndividual = ind
 		}
+		if fitness < worstFitness {
+			worstFitness = fitness
+		}
+		meanFitness += float64(fitness)
+	}
+	meanFitness = meanFitness / float64(len(pop))
+
+	variance := 0.0
+	for _, ind := range pop {
+		diff := float64(ind.GenotypeFitness()) - meanFitness
+		variance += diff * diff
 	}
+	variance = variance / float64(len(pop))
 
-	return best_result
+	bestQueenPositions := make([]int, len(bestIndividual.QueenPositions))
+	copy(bestQueenPositions, bestIndividual.QueenPositions)
+	bestFitness := bestIndividual.GenotypeFitness()
+	diversity := measureDiversity(pop)
+	return result.GenerationResult{
+		Generation:         generation,
+		BestQueenPositions: bestQueenPositions,
+		BestFitness:        bestFitness,
+		MeanFitness:        meanFitness,
+		WorstFitness:       worstFitness,
+		StdDevFitness:      math.Sqrt(variance),
+		Diversity:          diversity.Hamming,
+		UniqueGenotypes:    diversity.UniqueGenotypes,
+		PositionalEntropy:  diversity.PositionalEntropy,
+		IsSolution:         bestFitness == bestPossibleFitness,
+	}, bestIndividual
+}
+
+// Get the n best individuals without modifying the order of the population
+func bestIndividuals(pop []*individual.Individual, n int) []*individual.Individual {
+	sorted := slices.Clone(pop)
+	slices.SortStableFunc(sorted, func(a, b *individual.Individual) int {
+		return cmp.Compare(b.Fitness(), a.Fitness())
+	})
+	return sorted[:min(n, len(sorted))]
+}
+
+// Get the indices of the n worst individuals of the population
+func worstIndices(pop []*individual.Individual, n int) []int {
+	indices := make([]int, len(pop))
+	for i := range indices {
+		indices[i] = i
+	}
+	slices.SortStableFunc(indices, func(a, b int) int {
+		return cmp.Compare(pop[a].Fitness(), pop[b].Fitness())
+	})
+	return indices[:min(n, len(indices))]
 }
@@ -1,12 +1,21 @@
 package population
 
 import (
+	"context"
+	"math"
+	"reflect"
 	"testing"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
 )
 
 func Test_generateRandomIndividual(t *testing.T) {
 	numQueens := 8
-	ind := generateRandomIndividual(numQueens)
+	ind := generateRandomIndividual(util.NewRNG(1, 1), numQueens)
 
 	// Check if the individual has no queens in the same row
 	for i := 0; i < numQueens; i++ {
@@ -21,10 +30,240 @@ func Test_generateRandomIndividual(t *testing.T) {
 func TestGeneratePopulation(t *testing.T) {
 	numQueens := 8
 	populationSize := 100
-	population := Generate(numQueens, populationSize)
+	population := Generate(util.NewRNG(1, 1), numQueens, populationSize)
 
 	// Check if the population has the correct size
 	if len(population) != populationSize {
 		t.Errorf("GeneratePopulation() = %v, want %v", len(population), populationSize)
 	}
 }
+
+func testConfig() config.Config {
+	cfg := config.DefaultConfig
+	cfg.NumQueens = 12
+	cfg.PopulationSize = 50
+	cfg.MaxGenerations = 50
+	return cfg
+}
+
+func TestEvolveReproducible(t *testing.T) {
+	cfg := testConfig()
+	cfg.RecordHistory = true
+	run := func(seed uint64) result.RunResult {
+		rng := util.NewRNG(seed, 1)
+		pop := Generate(rng, cfg.NumQueens, cfg.PopulationSize)
+		return Evolve(context.Background(), rng, pop, cfg)
+	}
+
+	first := run(42)
+	second := run(42)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("Evolve() with the same seed = %v, want %v", second, first)
+	}
+}
+
+func TestEvolveHistory(t *testing.T) {
+	cfg := testConfig()
+	rng := util.NewRNG(1, 1)
+	pop := Generate(rng, cfg.NumQueens, cfg.PopulationSize)
+	r := Evolve(context.Background(), rng, pop, cfg)
+	if r.History != nil {
+		t.Errorf("Evolve() without history = %v, want no history", r.History)
+	}
+
+	cfg.RecordHistory = true
+	rng = util.NewRNG(1, 1)
+	pop = Generate(rng, cfg.NumQueens, cfg.PopulationSize)
+	r = Evolve(context.Background(), rng, pop, cfg)
+	if len(r.History) == 0 || len(r.History) > cfg.MaxGenerations {
+		t.Fatalf("Evolve() history has %v generations, want between 1 and %v", len(r.History), cfg.MaxGenerations)
+	}
+	for i, h := range r.History {
+		if h.Generation != i+1 {
+			t.Errorf("Evolve() history[%v].Generation = %v, want %v", i, h.Generation, i+1)
+		}
+		if float64(h.WorstFitness) > h.MeanFitness || h.MeanFitness > float64(h.BestFitness) {
+			t.Errorf("Evolve() history[%v] has worst %v, mean %v, best %v, want worst <= mean <= best", i, h.WorstFitness, h.MeanFitness, h.BestFitness)
+		}
+		if h.Diversity < 0 || h.Diversity > 1 {
+			t.Errorf("Evolve() history[%v].Diversity = %v, want between 0 and 1", i, h.Diversity)
+		}
+	}
+}
+
+func Test_measureDiversity(t *testing.T) {
+	same := []*individual.Individual{
+		{QueenPositions: []int{0, 1, 2, 3}},
+		{QueenPositions: []int{0, 1, 2, 3}},
+	}
+	if got, want := measureDiversity(same), (diversityMetrics{Hamming: 0, UniqueGenotypes: 1, PositionalEntropy: 0}); got != want {
+		t.Errorf("measureDiversity() of identical individuals = %v, want %v", got, want)
+	}
+
+	// Every row appears once in every column, so the entropy is maximal
+	different := []*individual.Individual{
+		{QueenPositions: []int{0, 1, 2, 3}},
+		{QueenPositions: []int{1, 0, 3, 2}},
+		{QueenPositions: []int{2, 3, 0, 1}},
+		{QueenPositions: []int{3, 2, 1, 0}},
+	}
+	got := measureDiversity(different)
+	if got.Hamming != 1 || got.UniqueGenotypes != 4 || math.Abs(got.PositionalEntropy-1) > 1e-9 {
+		t.Errorf("measureDiversity() of individuals differing in every column = %v, want {1 4 1}", got)
+	}
+}
+
+func TestEvolveStopReason(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumQueens = 100
+	cfg.MaxGenerations = 1000000
+
+	// A cancelled context stops the evolution after evaluating the first generation
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rng := util.NewRNG(1, 1)
+	r := Evolve(ctx, rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+	if r.Best.StopReason != result.StopInterrupted || r.Best.Generation != 1 {
+		t.Errorf("Evolve() with cancelled context stopped at generation %v with reason %v, want 1 and %v", r.Best.Generation, r.Best.StopReason, result.StopInterrupted)
+	}
+
+	// The maximum duration stops the evolution with a timeout
+	cfg.MaxDuration = config.Duration(10 * time.Millisecond)
+	r = Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+	if r.Best.StopReason != result.StopTimeout {
+		t.Errorf("Evolve() with maximum duration stopped with reason %v, want %v", r.Best.StopReason, result.StopTimeout)
+	}
+}
+
+func TestEvolvePanic(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumQueens = 8
+	cfg.PopulationSize = 1
+	cfg.MaxGenerations = 5
+
+	// A single individual breeds an empty generation, which panics when evaluated
+	for _, workers := range []int{1, 4} {
+		cfg.Workers = workers
+		rng := util.NewRNG(1, 1)
+		r := Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+		if r.Best.StopReason != result.StopError || r.Err == nil || r.Best.Generation != 1 {
+			t.Errorf("Evolve() with %v workers = %+v, %v, want the first generation stopped by an error", workers, r.Best, r.Err)
+		}
+	}
+}
+
+func TestEvolveLocalSearch(t *testing.T) {
+	for _, mode := range []config.LearningType{config.Lamarckian, config.Baldwinian} {
+		for _, target := range []config.LocalSearchTargetType{config.Offspring, config.Elites} {
+			t.Run(string(mode)+" "+string(target), func(t *testing.T) {
+				cfg := testConfig()
+				cfg.NumQueens = 30
+				cfg.MaxGenerations = 200
+				cfg.LocalSearchRate = 0.5
+				cfg.LocalSearchDepth = 30
+				cfg.LocalSearchMode = mode
+				cfg.LocalSearchTarget = target
+
+				rng := util.NewRNG(1, 1)
+				r := Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+				if !r.Best.IsSolution {
+					t.Errorf("Evolve() with local search = %v, want a solution", r.Best)
+				}
+				// The reported fitness must always be the one of the reported queens
+				ind := individual.Individual{QueenPositions: r.Best.BestQueenPositions}
+				if ind.Fitness() != r.Best.BestFitness {
+					t.Errorf("Evolve() best fitness = %v, want %v", r.Best.BestFitness, ind.Fitness())
+				}
+			})
+		}
+	}
+}
+
+func TestEvolveRestart(t *testing.T) {
+	for _, action := range []config.StagnationActionType{config.Reseed, config.Hypermutation} {
+		t.Run(string(action), func(t *testing.T) {
+			cfg := testConfig()
+			cfg.NumQueens = 50
+			cfg.RecordHistory = true
+			cfg.StagnationGenerations = 5
+			cfg.StagnationAction = action
+
+			rng := util.NewRNG(1, 1)
+			r := Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+			numRestarts := 0
+			for _, h := range r.History {
+				if h.Restarted {
+					numRestarts++
+				}
+			}
+			if numRestarts == 0 {
+				t.Errorf("Evolve() with stagnation detection did not restart the population")
+			}
+		})
+	}
+
+	// A diversity threshold above any possible diversity restarts the population in every generation
+	cfg := testConfig()
+	cfg.NumQueens = 50
+	cfg.MaxGenerations = 10
+	cfg.RecordHistory = true
+	cfg.DiversityThreshold = 1
+	rng := util.NewRNG(1, 1)
+	r := Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+	for i, h := range r.History[:len(r.History)-1] {
+		if !h.Restarted {
+			t.Errorf("Evolve() history[%v].Restarted = false, want true", i)
+		}
+	}
+}
+
+func TestEvolveWorkers(t *testing.T) {
+	cfg := testConfig()
+	cfg.NumQueens = 30
+	cfg.RecordHistory = true
+	cfg.Elitism = true
+	cfg.LocalSearchRate = 0.2
+	cfg.LocalSearchMode = config.Baldwinian
+	cfg.StagnationGenerations = 5
+
+	evolve := func(workers int) result.RunResult {
+		cfg := cfg
+		cfg.Workers = workers
+		rng := util.NewRNG(3, 1)
+		return Evolve(context.Background(), rng, Generate(rng, cfg.NumQueens, cfg.PopulationSize), cfg)
+	}
+
+	want := evolve(1)
+	for _, workers := range []int{2, 4, 16} {
+		if got := evolve(workers); !reflect.DeepEqual(got, want) {
+			t.Errorf("Evolve() with %v workers = %v, want %v", workers, got.Best, want.Best)
+		}
+	}
+}
+
+func Test_parallelFor(t *testing.T) {
+	for _, workers := range []int{1, 3, 100} {
+		calls := make([]int, 50)
+		parallelFor(workers, len(calls), func(i int) {
+			calls[i]++
+		})
+		for i, c := range calls {
+			if c != 1 {
+				t.Errorf("parallelFor() with %v workers called index %v %v times, want 1", workers, i, c)
+			}
+		}
+	}
+}
+
+func Test_parallelFor_panic(t *testing.T) {
+	defer func() {
+		if v := recover(); v != "failed" {
+			t.Errorf("parallelFor() panicked with %v, want the panic of fn", v)
+		}
+	}()
+	parallelFor(4, 50, func(i int) {
+		if i == 10 {
+			panic("failed")
+		}
+	})
+}
@@ -0,0 +1,370 @@
+package result
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+)
+
+// Represents the formats result files can be written in
+type Format string
+
+const (
+	FormatJSON     Format = "json"     // Pretty-printed object with the metadata and every result
+	FormatJSONL    Format = "jsonl"    // JSON Lines with the metadata first and then a result per line
+	FormatCSV      Format = "csv"      // CSV with the metadata as comments before the header
+	FormatColumnar Format = "columnar" // JSON object with the metadata and an array of values for every field
+)
+
+// Get every available format
+func Formats() []Format {
+	return []Format{FormatJSON, FormatJSONL, FormatCSV, FormatColumnar}
+}
+
+// Get the file extension of the format
+func (f Format) Extension() string {
+	switch f {
+	case FormatJSONL:
+		return ".jsonl"
+	case FormatCSV:
+		return ".csv"
+	default:
+		return ".json"
+	}
+}
+
+// Describes how a result file was produced, so it can be understood without knowing how it was run
+// The seed is the one of the configuration, which is never 0 once the runs have started
+// Command: arguments of the command line that produced the results, empty when they weren't produced from a command line
+type Metadata struct {
+	Config     config.Config `json:"config"`
+	StartedAt  time.Time     `json:"started_at"`
+	FinishedAt time.Time     `json:"finished_at"`
+	GoVersion  string        `json:"go_version"`
+	Host       string        `json:"host"`
+	Command    []string      `json:"command,omitempty"`
+}
+
+// Create the metadata of a result file produced by this process, given the command line that produced it if any
+func NewMetadata(cfg config.Config, startedAt, finishedAt time.Time, command []string) Metadata {
+	host, _ := os.Hostname()
+	return Metadata{
+		Config:     cfg,
+		StartedAt:  startedAt,
+		FinishedAt: finishedAt,
+		GoVersion:  runtime.Version(),
+		Host:       host,
+		Command:    command,
+	}
+}
+
+// Represents a field of a generation result written as a column
+type column struct {
+	name  string
+	value func(r GenerationResult) any
+}
+
+// Columns of the CSV and columnar formats, named like the JSON fields
+var columns = []column{
+	{"run_id", func(r GenerationResult) any { return r.RunID }},
+	{"seed", func(r GenerationResult) any { return r.Seed }},
+	{"generation", func(r GenerationResult) any { return r.Generation }},
+	{"best_fitness", func(r GenerationResult) any { return r.BestFitness }},
+	{"mean_fitness", func(r GenerationResult) any { return r.MeanFitness }},
+	{"worst_fitness", func(r GenerationResult) any { return r.WorstFitness }},
+	{"std_dev_fitness", func(r GenerationResult) any { return r.StdDevFitness }},
+	{"diversity", func(r GenerationResult) any { return r.Diversity }},
+	{"unique_genotypes", func(r GenerationResult) any { return r.UniqueGenotypes }},
+	{"positional_entropy", func(r GenerationResult) any { return r.PositionalEntropy }},
+	{"restarted", func(r GenerationResult) any { return r.Restarted }},
+	{"is_solution", func(r GenerationResult) any { return r.IsSolution }},
+	{"stop_reason", func(r GenerationResult) any { return r.StopReason }},
+	{"best_queen_positions", func(r GenerationResult) any { return r.BestQueenPositions }},
+}
+
+// Write generation results to w in the given format, preceded by their metadata
+func WriteResults(w io.Writer, format Format, meta Metadata, results []GenerationResult) error {
+	switch format {
+	case FormatJSON:
+		return writeJSON(w, meta, results)
+	case FormatJSONL:
+		return writeJSONL(w, meta, results)
+	case FormatCSV:
+		return writeCSV(w, meta, results)
+	case FormatColumnar:
+		return writeColumnar(w, meta, results)
+	default:
+		return fmt.Errorf("unknown result format %q", format)
+	}
+}
+
+func writeJSON(w io.Writer, meta Metadata, results []GenerationResult) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", " ")
+	return enc.Encode(struct {
+		Metadata Metadata           `json:"metadata"`
+		Results  []GenerationResult `json:"results"`
+	}{meta, results})
+}
+
+func writeJSONL(w io.Writer, meta Metadata, results []GenerationResult) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(struct {
+		Metadata Metadata `json:"metadata"`
+	}{meta}); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if err := enc.Encode(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeCSV(w io.Writer, meta Metadata, results []GenerationResult) error {
+	// Every line of the metadata is written as a comment, so CSV readers can skip it
+	data, err := json.MarshalIndent(meta, "", " ")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if _, err := fmt.Fprintln(w, "#", line); err != nil {
+			return err
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	for _, r := range results {
+		for i, c := range columns {
+			record[i] = fmt.Sprint(c.value(r))
+		}
+		record[len(record)-1] = strings.Trim(record[len(record)-1], "[]")
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Represents the columnar format, where every field of the results is stored as its own array
+type columnarFile struct {
+	Metadata Metadata         `json:"metadata"`
+	NumRows  int              `json:"num_rows"`
+	Columns  map[string][]any `json:"columns"`
+}
+
+func writeColumnar(w io.Writer, meta Metadata, results []GenerationResult) error {
+	file := columnarFile{Metadata: meta, NumRows: len(results), Columns: make(map[string][]any, len(columns))}
+	for _, c := range columns {
+		values := make([]any, len(results))
+		for i, r := range results {
+			values[i] = c.value(r)
+		}
+		file.Columns[c.name] = values
+	}
+	return json.NewEncoder(w).Encode(file)
+}
+
+// Read generation results and their metadata from r in the given format
+func ReadResults(r io.Reader, format Format) (Metadata, []GenerationResult, error) {
+	switch format {
+	case FormatJSON, FormatColumnar:
+		return readJSON(r)
+	case FormatJSONL:
+		return readJSONL(r)
+	case FormatCSV:
+		return readCSV(r)
+	default:
+		return Metadata{}, nil, fmt.Errorf("unknown result format %q", format)
+	}
+}
+
+// Read results written as JSON, in the columnar format or as a plain array of results without metadata
+func readJSON(r io.Reader) (Metadata, []GenerationResult, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+
+	var results []GenerationResult
+	if err := json.Unmarshal(data, &results); err == nil {
+		return Metadata{}, results, nil
+	}
+
+	var file struct {
+		Metadata Metadata                   `json:"metadata"`
+		Results  []GenerationResult         `json:"results"`
+		NumRows  int                        `json:"num_rows"`
+		Columns  map[string]json.RawMessage `json:"columns"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return Metadata{}, nil, err
+	}
+	if file.Columns == nil {
+		return file.Metadata, file.Results, nil
+	}
+
+	rows := make([]map[string]json.RawMessage, file.NumRows)
+	for i := range rows {
+		rows[i] = make(map[string]json.RawMessage, len(file.Columns))
+	}
+	for name, data := range file.Columns {
+		var values []json.RawMessage
+		if err := json.Unmarshal(data, &values); err != nil {
+			return Metadata{}, nil, fmt.Errorf("column %v: %w", name, err)
+		}
+		if len(values) != file.NumRows {
+			return Metadata{}, nil, fmt.Errorf("column %v has %v rows, want %v", name, len(values), file.NumRows)
+		}
+		for i, v := range values {
+			rows[i][name] = v
+		}
+	}
+	results, err = decodeRows(rows)
+	return file.Metadata, results, err
+}
+
+// Decode results stored as a JSON value for every field of every row, as the CSV and columnar formats are
+func decodeRows(rows []map[string]json.RawMessage) ([]GenerationResult, error) {
+	data, err := json.Marshal(rows)
+	if err != nil {
+		return nil, err
+	}
+	results := []GenerationResult{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&results); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+func readJSONL(r io.Reader) (Metadata, []GenerationResult, error) {
+	var meta Metadata
+	results := []GenerationResult{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if line == 1 {
+			var header struct {
+				Metadata *Metadata `json:"metadata"`
+			}
+			if err := json.Unmarshal(scanner.Bytes(), &header); err == nil && header.Metadata != nil {
+				meta = *header.Metadata
+				continue
+			}
+		}
+
+		var result GenerationResult
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			return Metadata{}, nil, fmt.Errorf("line %v: %w", line, err)
+		}
+		results = append(results, result)
+	}
+	return meta, results, scanner.Err()
+}
+
+func readCSV(r io.Reader) (Metadata, []GenerationResult, error) {
+	// Collect the metadata from the comments before the header
+	br := bufio.NewReader(r)
+	var metaJSON strings.Builder
+	for {
+		b, err := br.Peek(1)
+		if err != nil || b[0] != '#' {
+			break
+		}
+		line, err := br.ReadString('\n')
+		metaJSON.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "#"), " "))
+		if err != nil {
+			break
+		}
+	}
+	var meta Metadata
+	if metaJSON.Len() > 0 {
+		if err := json.Unmarshal([]byte(metaJSON.String()), &meta); err != nil {
+			return Metadata{}, nil, fmt.Errorf("metadata: %w", err)
+		}
+	}
+
+	records, err := csv.NewReader(br).ReadAll()
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+	if len(records) == 0 {
+		return Metadata{}, nil, errors.New("missing header")
+	}
+
+	// Every field is turned into its JSON value, so rows are decoded like the columnar format
+	header := records[0]
+	rows := make([]map[string]json.RawMessage, len(records)-1)
+	for i, record := range records[1:] {
+		rows[i] = make(map[string]json.RawMessage, len(header))
+		for j, field := range record {
+			switch header[j] {
+			case "stop_reason":
+				rows[i][header[j]], _ = json.Marshal(field)
+			case "best_queen_positions":
+				rows[i][header[j]] = json.RawMessage("[" + strings.Join(strings.Fields(field), ",") + "]")
+			default:
+				rows[i][header[j]] = json.RawMessage(field)
+			}
+		}
+	}
+	results, err := decodeRows(rows)
+	if err != nil {
+		return Metadata{}, nil, err
+	}
+	return meta, results, nil
+}
+
+// Get the format of a result file from its extension, JSON files may be written in the columnar format as well
+func FormatFromPath(path string) Format {
+	switch filepath.Ext(path) {
+	case ".jsonl":
+		return FormatJSONL
+	case ".csv":
+		return FormatCSV
+	default:
+		return FormatJSON
+	}
+}
+
+// Create a file that doesn't exist yet, adding a numeric suffix to its name if needed, and return it
+// Existing files are only replaced when overwrite is set
+func CreateResultFile(path string, overwrite bool) (*os.File, error) {
+	if overwrite {
+		return os.Create(path)
+	}
+
+	ext := filepath.Ext(path)
+	base := strings.TrimSuffix(path, ext)
+	candidate := path
+	for i := 1; ; i++ {
+		file, err := os.OpenFile(candidate, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, os.ErrExist) {
+			return file, err
+		}
+		candidate = fmt.Sprintf("%v-%v%v", base, i, ext)
+	}
+}
@@ -0,0 +1,80 @@
+package result
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+)
+
+func TestWriteAndReadResults(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.Seed = 42
+	meta := Metadata{
+		Config:     cfg,
+		StartedAt:  time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+		FinishedAt: time.Date(2024, 5, 1, 10, 5, 30, 0, time.UTC),
+		GoVersion:  "go1.22.0",
+		Host:       "localhost",
+		Command:    []string{"genetic-n-queens", "run", "-seed", "42"},
+	}
+	results := []GenerationResult{
+		{RunID: 1, Seed: 42, BestQueenPositions: []int{1, 3, 0, 2}, Generation: 5, BestFitness: 6, MeanFitness: 4.5, WorstFitness: 2, StdDevFitness: 1.25, Diversity: 0.5, UniqueGenotypes: 10, PositionalEntropy: 0.75, IsSolution: true, StopReason: StopSolution},
+		{RunID: 2, Seed: 42, BestQueenPositions: []int{0, 1, 2, 3}, Generation: 10, Restarted: true},
+	}
+
+	for _, format := range Formats() {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteResults(&buf, format, meta, results); err != nil {
+				t.Fatalf("WriteResults() error = %v", err)
+			}
+
+			gotMeta, got, err := ReadResults(&buf, format)
+			if err != nil {
+				t.Fatalf("ReadResults() error = %v", err)
+			}
+			if !reflect.DeepEqual(gotMeta, meta) {
+				t.Errorf("ReadResults() metadata = %v, want %v", gotMeta, meta)
+			}
+			if !reflect.DeepEqual(got, results) {
+				t.Errorf("ReadResults() = %v, want %v", got, results)
+			}
+		})
+	}
+
+	if err := WriteResults(&bytes.Buffer{}, "xml", meta, results); err == nil {
+		t.Errorf("WriteResults() with unknown format error = nil, want an error")
+	}
+}
+
+func TestCreateResultFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "results.json")
+	want := []string{path, filepath.Join(dir, "results-1.json"), filepath.Join(dir, "results-2.json")}
+	for _, w := range want {
+		file, err := CreateResultFile(path, false)
+		if err != nil {
+			t.Fatalf("CreateResultFile() error = %v", err)
+		}
+		file.WriteString("[]")
+		file.Close()
+		if file.Name() != w {
+			t.Errorf("CreateResultFile() = %v, want %v", file.Name(), w)
+		}
+	}
+
+	// Overwriting replaces the file itself
+	file, err := CreateResultFile(path, true)
+	if err != nil {
+		t.Fatalf("CreateResultFile() error = %v", err)
+	}
+	file.Close()
+	if data, _ := os.ReadFile(path); file.Name() != path || len(data) != 0 {
+		t.Errorf("CreateResultFile() with overwrite = %v with %q, want empty %v", file.Name(), data, path)
+	}
+}
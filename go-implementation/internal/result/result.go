@@ -1,33 +1,64 @@
 package result
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
 )
 
+// Represents why a run of the genetic algorithm stopped
+type StopReason string
+
+const (
+	StopSolution       StopReason = "solution"
+	StopMaxGenerations StopReason = "max_generations"
+	StopTimeout        StopReason = "timeout"
+	StopInterrupted    StopReason = "interrupted"
+	StopError          StopReason = "error"
+)
+
 // Represents the result of a single generation of the genetic algorithm
 type GenerationResult struct {
-	BestQueenPositions []int   `json:"best_queen_positions"`
-	Generation         int     `json:"generation"`
-	BestFitness        int     `json:"best_fitness"`
-	MeanFitness        float64 `json:"mean_fitness"`
-	IsSolution         bool    `json:"is_solution"`
+	RunID              int        `json:"run_id"`
+	Seed               uint64     `json:"seed"`
+	BestQueenPositions []int      `json:"best_queen_positions"`
+	Generation         int        `json:"generation"`
+	BestFitness        int        `json:"best_fitness"`
+	MeanFitness        float64    `json:"mean_fitness"`
+	WorstFitness       int        `json:"worst_fitness"`
+	StdDevFitness      float64    `json:"std_dev_fitness"`
+	Diversity          float64    `json:"diversity"`
+	UniqueGenotypes    int        `json:"unique_genotypes"`
+	PositionalEntropy  float64    `json:"positional_entropy"`
+	Restarted          bool       `json:"restarted,omitempty"`
+	IsSolution         bool       `json:"is_solution"`
+	StopReason         StopReason `json:"stop_reason,omitempty"`
+}
+
+// Represents the result of a single run of the genetic algorithm
+// History contains every generation of the run and is only filled when history recording is enabled
+// The stop reason of the run is only set on the best generation
+// Err: why the run stopped when its stop reason is StopError
+type RunResult struct {
+	Best    GenerationResult
+	History []GenerationResult
+	Err     error
 }
 
-// Save a slice of generation results to a file in JSON format
-func SaveResultsToFile(results []GenerationResult, path string) error {
-	file, err := json.MarshalIndent(results, "", " ")
+// Load a slice of generation results from a file in any format, which is detected from its extension
+// JSON files can also be plain arrays of results without metadata
+func LoadResultsFromFile(path string) ([]GenerationResult, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return fmt.Errorf("error marshalling results to JSON: %v", err)
+		return nil, fmt.Errorf("error reading results from file: %v", err)
 	}
+	defer file.Close()
 
-	err = os.WriteFile(path, file, 0644)
+	_, results, err := ReadResults(file, FormatFromPath(path))
 	if err != nil {
-		return fmt.Errorf("error writing results to file: %v", err)
+		return nil, fmt.Errorf("error reading results from %v: %v", path, err)
 	}
 
-	return nil
+	return results, nil
 }
 
 // Get the best fitness of a slice of generation results
@@ -88,3 +119,14 @@ func GetNumSolutions(results []GenerationResult) int {
 	}
 	return numSolutions
 }
+
+// Get the number of runs that were interrupted before finishing
+func GetNumInterrupted(results []GenerationResult) int {
+	numInterrupted := 0
+	for _, result := range results {
+		if result.StopReason == StopInterrupted {
+			numInterrupted++
+		}
+	}
+	return numInterrupted
+}
@@ -1,6 +1,40 @@
 package result
 
-import "testing"
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadResultsFromFile(t *testing.T) {
+	results := []GenerationResult{
+		{RunID: 1, Seed: 42, BestQueenPositions: []int{1, 3, 0, 2}, Generation: 5, BestFitness: 6, IsSolution: true, StopReason: StopSolution},
+		{RunID: 2, Seed: 42, BestQueenPositions: []int{0, 1, 2, 3}, Generation: 10, BestFitness: 0, StopReason: StopMaxGenerations},
+	}
+	// A plain array of results, as written before results had metadata
+	data, err := json.Marshal(results)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "results.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadResultsFromFile(path)
+	if err != nil {
+		t.Fatalf("LoadResultsFromFile() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, results) {
+		t.Errorf("LoadResultsFromFile() = %v, want %v", got, results)
+	}
+
+	if _, err := LoadResultsFromFile(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Errorf("LoadResultsFromFile() of a missing file error = nil, want an error")
+	}
+}
 
 func TestGetBestFitness(t *testing.T) {
 	type args struct {
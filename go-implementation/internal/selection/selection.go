@@ -9,11 +9,11 @@ import (
 )
 
 // Select individuals from the population using the tournament method
-func SelectByTournament(population []*individual.Individual, size int) []*individual.Individual {
+func SelectByTournament(rng *rand.Rand, population []*individual.Individual, size int) []*individual.Individual {
 	selected := []*individual.Individual{}
 	for len(selected) < len(population) {
 		// Get size random individuals
-		samples := util.Sample(population, size)
+		samples := util.Sample(rng, population, size)
 
 		// Select best individual
 		bestIndividual := samples[0]
@@ -29,7 +29,7 @@ func SelectByTournament(population []*individual.Individual, size int) []*indivi
 }
 
 // Select individuals from the population using the roulette method
-func SelectByRoulette(population []*individual.Individual) []*individual.Individual {
+func SelectByRoulette(rng *rand.Rand, population []*individual.Individual) []*individual.Individual {
 	selected := []*individual.Individual{}
 	totalFitness := 0
 	for _, ind := range population {
@@ -48,7 +48,7 @@ func SelectByRoulette(population []*individual.Individual) []*individual.Individ
 	}
 
 	for len(selected) < len(population) {
-		r := rand.Float64()
+		r := rng.Float64()
 		for i, p := range cummulative_probabilities {
 			if r <= p {
 				selected = append(selected, population[i])
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+// Number of events buffered for every client of an event stream
+// Events are dropped while the buffer of a client is full, so slow clients never stall the runs
+const eventBuffer = 256
+
+// Interval between comments sent to keep idle event streams open through proxies
+const keepAliveInterval = 15 * time.Second
+
+// Represents a generation of a run sent to the event stream of a job, or the last one once the run stops
+// BestQueenPositions, BestFitness, MeanFitness, Diversity: statistics of the generation
+// BestFitnessSoFar: best fitness of every generation of the run so far
+// Dropped: number of events of the job dropped for this client since the previous one, because it was reading too slowly
+type Event struct {
+	RunID              int               `json:"run_id"`
+	Generation         int               `json:"generation"`
+	MaxGenerations     int               `json:"max_generations"`
+	BestQueenPositions []int             `json:"best_queen_positions"`
+	BestFitness        int               `json:"best_fitness"`
+	MeanFitness        float64           `json:"mean_fitness"`
+	Diversity          float64           `json:"diversity"`
+	BestFitnessSoFar   int               `json:"best_fitness_so_far"`
+	ElapsedSeconds     float64           `json:"elapsed_seconds"`
+	StopReason         result.StopReason `json:"stop_reason,omitempty"`
+	Dropped            int               `json:"dropped,omitempty"`
+}
+
+func newEvent(p nqueens.Progress) Event {
+	return Event{
+		RunID:              p.RunID,
+		Generation:         p.Generation,
+		MaxGenerations:     p.MaxGenerations,
+		BestQueenPositions: p.Current.BestQueenPositions,
+		BestFitness:        p.Current.BestFitness,
+		MeanFitness:        p.Current.MeanFitness,
+		Diversity:          p.Current.Diversity,
+		BestFitnessSoFar:   p.Best.BestFitness,
+		ElapsedSeconds:     p.Elapsed.Seconds(),
+		StopReason:         p.StopReason,
+	}
+}
+
+// Represents a client of the event stream of a job
+// Its channel is closed once the job finishes
+type subscriber struct {
+	events  chan Event
+	dropped int // Guarded by the mutex of the job
+}
+
+// Subscribe to the events of the job with a buffer of the given size
+// The channel of the subscriber is already closed if the job has finished
+func (j *job) subscribe(buffer int) *subscriber {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	sub := &subscriber{events: make(chan Event, buffer)}
+	if j.state.Finished() {
+		close(sub.events)
+		return sub
+	}
+	j.subscribers[sub] = struct{}{}
+	return sub
+}
+
+// Stop sending events to a subscriber that is going away
+func (j *job) unsubscribe(sub *subscriber) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.subscribers, sub)
+}
+
+// Send an event to every subscriber without waiting, dropping it for the ones whose buffer is full
+// Must be called with the mutex of the job held
+func (j *job) publish(e Event) {
+	for sub := range j.subscribers {
+		e.Dropped = sub.dropped
+		select {
+		case sub.events <- e:
+			sub.dropped = 0
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+// Close the channel of every subscriber, as no more events will be sent
+// Must be called with the mutex of the job held
+func (j *job) closeSubscribers() {
+	for sub := range j.subscribers {
+		close(sub.events)
+	}
+	clear(j.subscribers)
+}
+
+// Stream the progress of a job as Server-Sent Events: a status event with the state of the job, a generation event
+// for every generation of every run, and an end event with the final state of the job once it finishes
+func (s *Server) events(w http.ResponseWriter, r *http.Request) {
+	j := s.job(w, r)
+	if j == nil {
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("streaming isn't supported"))
+		return
+	}
+
+	// Subscribe before getting the status so no generation is missed in between
+	sub := j.subscribe(eventBuffer)
+	defer j.unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	writeEvent(w, "status", j.status())
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(keepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-sub.events:
+			if !ok {
+				writeEvent(w, "end", j.status())
+				flusher.Flush()
+				return
+			}
+			writeEvent(w, "generation", e)
+			flusher.Flush()
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Write a Server-Sent Event with the JSON encoding of v as its data
+func writeEvent(w http.ResponseWriter, name string, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", name, data)
+}
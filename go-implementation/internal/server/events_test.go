@@ -0,0 +1,135 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+// Represents a Server-Sent Event read from a stream
+type sse struct {
+	name string
+	data string
+}
+
+// Read the next event of a stream, skipping comments
+func readEvent(t *testing.T, r *bufio.Reader) sse {
+	t.Helper()
+	var e sse
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatalf("read event: %v", err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		switch {
+		case line == "" && e.name != "":
+			return e
+		case strings.HasPrefix(line, "event: "):
+			e.name = strings.TrimPrefix(line, "event: ")
+		case strings.HasPrefix(line, "data: "):
+			e.data = strings.TrimPrefix(line, "data: ")
+		}
+	}
+}
+
+func TestServer_events(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions)
+	do(t, http.MethodPost, ts.URL+"/jobs", slowConfig, nil)
+
+	resp, err := http.Get(ts.URL + "/jobs/1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("GET /jobs/1/events content type = %v, want text/event-stream", ct)
+	}
+	r := bufio.NewReader(resp.Body)
+
+	var status JobStatus
+	if e := readEvent(t, r); e.name != "status" || json.Unmarshal([]byte(e.data), &status) != nil || status.ID != "1" {
+		t.Fatalf("GET /jobs/1/events first event = %+v, want the status of job 1", e)
+	}
+
+	// Every run sends its generations in order
+	last := map[int]int{}
+	for i := 0; i < 20; i++ {
+		e := readEvent(t, r)
+		var g Event
+		if e.name != "generation" || json.Unmarshal([]byte(e.data), &g) != nil {
+			t.Fatalf("GET /jobs/1/events event = %+v, want a generation", e)
+		}
+		if g.Generation <= last[g.RunID] || len(g.BestQueenPositions) != 200 || g.BestFitness > g.BestFitnessSoFar || g.Diversity <= 0 {
+			t.Errorf("GET /jobs/1/events generation = %+v after generation %v of the run", g, last[g.RunID])
+		}
+		last[g.RunID] = g.Generation
+	}
+
+	// The stream ends with the final status once the job is cancelled
+	do(t, http.MethodPost, ts.URL+"/jobs/1/cancel", "", nil)
+	for {
+		e := readEvent(t, r)
+		if e.name == "generation" {
+			continue
+		}
+		if e.name != "end" || json.Unmarshal([]byte(e.data), &status) != nil || status.State != StateCancelled {
+			t.Errorf("GET /jobs/1/events last event = %+v, want the end of a cancelled job", e)
+		}
+		break
+	}
+
+	// A finished job only sends its status and the end of the stream
+	resp, err = http.Get(ts.URL + "/jobs/1/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	r = bufio.NewReader(resp.Body)
+	if e := readEvent(t, r); e.name != "status" {
+		t.Errorf("GET /jobs/1/events of a finished job first event = %v, want status", e.name)
+	}
+	if e := readEvent(t, r); e.name != "end" {
+		t.Errorf("GET /jobs/1/events of a finished job second event = %v, want end", e.name)
+	}
+}
+
+func TestJob_publish(t *testing.T) {
+	cfg := config.DefaultConfig
+	cfg.Seed = 1
+	j, err := newJob(context.Background(), "1", cfg, nil, slog.Default())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A client that doesn't read doesn't block the runs, and is told how many events it missed
+	sub := j.subscribe(2)
+	for generation := 1; generation <= 5; generation++ {
+		j.observe(nqueens.Progress{RunID: 1, Generation: generation})
+	}
+	for _, want := range []int{1, 2} {
+		if e := <-sub.events; e.Generation != want || e.Dropped != 0 {
+			t.Errorf("publish() event = generation %v with %v dropped, want generation %v", e.Generation, e.Dropped, want)
+		}
+	}
+	j.observe(nqueens.Progress{RunID: 1, Generation: 6})
+	if e := <-sub.events; e.Generation != 6 || e.Dropped != 3 {
+		t.Errorf("publish() event = generation %v with %v dropped, want generation 6 with 3 dropped", e.Generation, e.Dropped)
+	}
+
+	// Subscribers are closed once the job finishes
+	j.stop()
+	if _, ok := <-sub.events; ok {
+		t.Errorf("subscriber of a finished job is open, want it closed")
+	}
+	if _, ok := <-j.subscribe(2).events; ok {
+		t.Errorf("subscribe() to a finished job is open, want it closed")
+	}
+}
@@ -0,0 +1,211 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/metrics"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/pkg/nqueens"
+)
+
+// Represents the state of a job
+type State string
+
+const (
+	StateQueued    State = "queued"
+	StateRunning   State = "running"
+	StateDone      State = "done"
+	StateCancelled State = "cancelled"
+	StateFailed    State = "failed"
+)
+
+// Check whether a job in the state has stopped and won't change anymore
+func (s State) Finished() bool {
+	return s == StateDone || s == StateCancelled || s == StateFailed
+}
+
+// Represents a configuration submitted to the server, solved by one of its workers
+type job struct {
+	id     string
+	solver *nqueens.Solver
+	logger *slog.Logger
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu          sync.Mutex
+	state       State
+	runs        []nqueens.Progress // Last progress of every run, by run ID - 1
+	subscribers map[*subscriber]struct{}
+	result      *nqueens.Result
+	err         error // Why the runs of a failed job stopped
+	createdAt   time.Time
+	startedAt   time.Time
+	finishedAt  time.Time
+}
+
+// Create a queued job solving the configuration, cancelled along with the context
+// The metrics of its runs are collected to m unless it is nil, and its lifecycle is logged with the job ID and the configuration hash
+func newJob(ctx context.Context, id string, cfg config.Config, m *metrics.Metrics, logger *slog.Logger) (*job, error) {
+	j := &job{id: id, state: StateQueued, subscribers: map[*subscriber]struct{}{}, createdAt: time.Now()}
+	opts := []nqueens.Option{nqueens.OnGeneration(j.observe)}
+	if m != nil {
+		opts = append(opts, nqueens.OnGeneration(m.Observer(id)))
+	}
+	solver, err := nqueens.New(cfg, opts...)
+	if err != nil {
+		return nil, err
+	}
+	j.solver = solver
+	j.logger = logger.With("job_id", id, "config_hash", solver.Config().Hash())
+	j.ctx, j.cancel = context.WithCancel(ctx)
+
+	// Runs that haven't reported any progress yet are at generation 0
+	j.runs = make([]nqueens.Progress, solver.Config().NumRuns)
+	for i := range j.runs {
+		j.runs[i] = nqueens.Progress{RunID: i + 1, MaxGenerations: solver.Config().MaxGenerations}
+	}
+	return j, nil
+}
+
+// Solve the configuration of the job unless it was cancelled while queued
+func (j *job) run() {
+	j.mu.Lock()
+	if j.state != StateQueued {
+		j.mu.Unlock()
+		return
+	}
+	if j.ctx.Err() != nil {
+		j.finish(StateCancelled)
+		j.mu.Unlock()
+		return
+	}
+	j.state = StateRunning
+	j.startedAt = time.Now()
+	j.logger.Info("job started", "waited", j.startedAt.Sub(j.createdAt))
+	j.mu.Unlock()
+
+	res, err := j.solve()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.result = res
+	switch {
+	case err != nil && j.ctx.Err() == nil:
+		j.err = err
+		j.finish(StateFailed)
+	case err != nil:
+		j.finish(StateCancelled)
+	default:
+		j.finish(StateDone)
+	}
+}
+
+// Solve the configuration, turning a panic of the solver into an error so a bad job fails without stopping the server
+func (j *job) solve() (res *nqueens.Result, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = fmt.Errorf("solver panicked: %v", v)
+		}
+	}()
+	return j.solver.Solve(j.ctx)
+}
+
+// Cancel the job, a running job keeps the results found so far
+func (j *job) stop() {
+	j.cancel()
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.state == StateQueued {
+		j.finish(StateCancelled)
+	}
+}
+
+func (j *job) finish(state State) {
+	j.state = state
+	j.finishedAt = time.Now()
+	j.cancel()
+	j.closeSubscribers()
+
+	// Jobs cancelled while queued have no results
+	level := slog.LevelInfo
+	attrs := []any{"state", state}
+	if j.result != nil {
+		attrs = append(attrs, "solutions", j.result.NumSolutions(), "best_fitness", j.result.BestFitness(), "elapsed", j.result.Elapsed())
+	}
+	if j.err != nil {
+		level = slog.LevelError
+		attrs = append(attrs, "error", j.err)
+	}
+	j.logger.Log(context.Background(), level, "job finished", attrs...)
+}
+
+// Record the progress of a run and send it to the subscribers of the job
+// Only evaluated generations and the end of the run are sent, not its start
+func (j *job) observe(p nqueens.Progress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if p.RunID >= 1 && p.RunID <= len(j.runs) {
+		j.runs[p.RunID-1] = p
+	}
+	if p.Generation > 0 || p.Done() {
+		j.publish(newEvent(p))
+	}
+}
+
+// Represents a job in the responses of the server
+// StartedAt and FinishedAt are only set once the job has started or finished
+// Error: why the runs of a failed job stopped
+type JobStatus struct {
+	ID         string        `json:"id"`
+	State      State         `json:"state"`
+	Config     config.Config `json:"config"`
+	Runs       []RunStatus   `json:"runs"`
+	CreatedAt  time.Time     `json:"created_at"`
+	StartedAt  *time.Time    `json:"started_at,omitempty"`
+	FinishedAt *time.Time    `json:"finished_at,omitempty"`
+	Error      string        `json:"error,omitempty"`
+}
+
+// Represents the progress of a run of a job
+// Best: best generation so far, not set until the first generation has been evaluated
+// StopReason: why the run stopped, only set once it has stopped
+type RunStatus struct {
+	RunID          int                      `json:"run_id"`
+	Generation     int                      `json:"generation"`
+	MaxGenerations int                      `json:"max_generations"`
+	ElapsedSeconds float64                  `json:"elapsed_seconds"`
+	Best           *result.GenerationResult `json:"best,omitempty"`
+	StopReason     result.StopReason        `json:"stop_reason,omitempty"`
+}
+
+// Get the state and progress of the job
+func (j *job) status() JobStatus {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	s := JobStatus{ID: j.id, State: j.state, Config: j.solver.Config(), CreatedAt: j.createdAt}
+	if started := j.startedAt; !started.IsZero() {
+		s.StartedAt = &started
+	}
+	if finished := j.finishedAt; !finished.IsZero() {
+		s.FinishedAt = &finished
+	}
+	if j.err != nil {
+		s.Error = j.err.Error()
+	}
+	for _, p := range j.runs {
+		r := RunStatus{RunID: p.RunID, Generation: p.Generation, MaxGenerations: p.MaxGenerations, ElapsedSeconds: p.Elapsed.Seconds(), StopReason: p.StopReason}
+		if p.Generation > 0 {
+			best := p.Best
+			r.Best = &best
+		}
+		s.Runs = append(s.Runs, r)
+	}
+	return s
+}
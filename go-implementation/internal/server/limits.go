@@ -0,0 +1,44 @@
+package server
+
+import (
+	"fmt"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+)
+
+// Upper limits of the configurations the server accepts, so a single job can't exhaust its memory or keep its workers busy forever
+// A limit of 0 leaves its field unlimited
+type Limits struct {
+	NumRuns        int
+	PopulationSize int
+	NumQueens      int
+	MaxGenerations int
+	Workers        int
+}
+
+// Default limits of the server, a population at these limits takes about 16 MB
+var DefaultLimits = Limits{NumRuns: 32, PopulationSize: 2000, NumQueens: 1000, MaxGenerations: 1000000, Workers: 8}
+
+// Check a configuration against the limits, reporting every field above its limit in a ValidationError
+func (l Limits) check(cfg config.Config) error {
+	v := &config.ValidationError{}
+	for _, f := range []struct {
+		path  string
+		value int
+		limit int
+	}{
+		{"num_runs", cfg.NumRuns, l.NumRuns},
+		{"population_size", cfg.PopulationSize, l.PopulationSize},
+		{"num_queens", cfg.NumQueens, l.NumQueens},
+		{"max_generations", cfg.MaxGenerations, l.MaxGenerations},
+		{"workers", cfg.Workers, l.Workers},
+	} {
+		if f.limit > 0 && f.value > f.limit {
+			v.Errors = append(v.Errors, config.FieldError{Path: f.path, Reason: fmt.Sprintf("must be at most %v on this server", f.limit)})
+		}
+	}
+	if len(v.Errors) > 0 {
+		return v
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+// Package server solves configurations submitted over HTTP as jobs, queued and run by a fixed number of workers
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/metrics"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Maximum size of the body of a request
+const maxBodySize = 1 << 20
+
+// Changes how the server runs jobs
+// Workers: number of jobs solved at the same time, every run of a job still evolves in its own goroutine
+// QueueSize: number of jobs waiting for a worker, further jobs are rejected until one starts or is cancelled
+// Retain: number of finished jobs kept so their results can be fetched, the oldest ones are forgotten first
+// Limits: upper limits of the submitted configurations, larger ones are rejected before creating their jobs
+// Metrics: metrics the runs of every job are collected to, labelled by job ID, nil to not collect them
+// Logger: logs when every job is queued, starts and finishes, nil to not log them
+type Options struct {
+	Workers   int
+	QueueSize int
+	Retain    int
+	Limits    Limits
+	Metrics   *metrics.Metrics
+	Logger    *slog.Logger
+}
+
+// Default options of the server
+var DefaultOptions = Options{Workers: 1, QueueSize: 100, Retain: 100, Limits: DefaultLimits}
+
+// Represents a solver service: configurations are submitted as jobs, which can be followed, cancelled and fetched once finished
+//
+//	POST /jobs                 submit a configuration, omitted fields keep their default values
+//	GET  /jobs                 list the jobs in submission order
+//	GET  /jobs/{id}            get the state and progress of every run of a job
+//	GET  /jobs/{id}/results    get the best generation of every run of a finished job, as in a JSON results file
+//	GET  /jobs/{id}/events     stream the statistics of every generation of every run of a job as Server-Sent Events
+//	POST /jobs/{id}/cancel     cancel a job, a running job keeps the results found so far
+type Server struct {
+	opts   Options
+	mux    *http.ServeMux
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu     sync.Mutex
+	queued *sync.Cond // Signalled when a job is queued or the server is closed
+	queue  []*job     // Jobs waiting for a worker in submission order, cancelled jobs leave it at once
+	jobs   map[string]*job
+	order  []*job // Jobs in submission order
+	nextID int
+	closed bool
+}
+
+// Create a server and start its workers, which run until the server is closed
+func New(opts Options) *Server {
+	opts.Workers = max(opts.Workers, 1)
+	opts.QueueSize = max(opts.QueueSize, 0)
+	opts.Retain = max(opts.Retain, 0)
+	if opts.Logger == nil {
+		opts.Logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	s := &Server{
+		opts:   opts,
+		mux:    http.NewServeMux(),
+		jobs:   map[string]*job{},
+		nextID: 1,
+	}
+	s.queued = sync.NewCond(&s.mu)
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.mux.HandleFunc("POST /jobs", s.submit)
+	s.mux.HandleFunc("GET /jobs", s.list)
+	s.mux.HandleFunc("GET /jobs/{id}", s.get)
+	s.mux.HandleFunc("GET /jobs/{id}/results", s.results)
+	s.mux.HandleFunc("GET /jobs/{id}/events", s.events)
+	s.mux.HandleFunc("POST /jobs/{id}/cancel", s.stop)
+
+	for range opts.Workers {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for j := s.next(); j != nil; j = s.next() {
+				j.run()
+			}
+		}()
+	}
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// Cancel every job and wait for the workers to stop, new jobs are rejected
+// Running jobs keep the results found so far
+func (s *Server) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		s.cancel()
+		s.queued.Broadcast()
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// Wait for a queued job and take it from the queue
+// Once the server is closed the jobs left are still taken, so they finish as cancelled, and then nil is returned
+func (s *Server) next() *job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for len(s.queue) == 0 && !s.closed {
+		s.queued.Wait()
+	}
+	if len(s.queue) == 0 {
+		return nil
+	}
+	j := s.queue[0]
+	s.queue = slices.Delete(s.queue, 0, 1)
+	return j
+}
+
+// Queue a job for the configuration in the body of the request
+func (s *Server) submit(w http.ResponseWriter, r *http.Request) {
+	var fields map[string]json.RawMessage
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&fields); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	layered := config.NewLayered()
+	if err := layered.ApplyJSON(fields, config.SourceRequest); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err := s.opts.Limits.check(layered.Config); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		writeError(w, http.StatusServiceUnavailable, errors.New("the server is shutting down"))
+		return
+	}
+	if len(s.queue) >= s.opts.QueueSize {
+		writeError(w, http.StatusServiceUnavailable, errors.New("the queue is full, try again later"))
+		return
+	}
+	j, err := newJob(s.ctx, strconv.Itoa(s.nextID), layered.Config, s.opts.Metrics, s.opts.Logger)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.queue = append(s.queue, j)
+	s.queued.Signal()
+	j.logger.Info("job queued", "runs", j.solver.Config().NumRuns, "queued_jobs", len(s.queue))
+
+	s.nextID++
+	s.jobs[j.id] = j
+	s.order = append(s.order, j)
+	s.forget()
+
+	w.Header().Set("Location", "/jobs/"+j.id)
+	writeJSON(w, http.StatusAccepted, j.status())
+}
+
+// Forget the oldest finished jobs beyond the number of retained ones
+func (s *Server) forget() {
+	finished := 0
+	for _, j := range s.order {
+		if j.status().State.Finished() {
+			finished++
+		}
+	}
+
+	kept := s.order[:0]
+	for _, j := range s.order {
+		if finished > s.opts.Retain && j.status().State.Finished() {
+			delete(s.jobs, j.id)
+			if s.opts.Metrics != nil {
+				s.opts.Metrics.Forget(j.id)
+			}
+			finished--
+			continue
+		}
+		kept = append(kept, j)
+	}
+	clear(s.order[len(kept):])
+	s.order = kept
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	jobs := make([]JobStatus, len(s.order))
+	for i, j := range s.order {
+		jobs[i] = j.status()
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	if j := s.job(w, r); j != nil {
+		writeJSON(w, http.StatusOK, j.status())
+	}
+}
+
+func (s *Server) results(w http.ResponseWriter, r *http.Request) {
+	j := s.job(w, r)
+	if j == nil {
+		return
+	}
+
+	j.mu.Lock()
+	state, res := j.state, j.result
+	j.mu.Unlock()
+	if !state.Finished() {
+		writeError(w, http.StatusConflict, errors.New("job "+j.id+" is "+string(state)+", its results aren't available until it finishes"))
+		return
+	}
+	if res == nil {
+		writeError(w, http.StatusConflict, errors.New("job "+j.id+" is "+string(state)+" without any results"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	result.WriteResults(w, result.FormatJSON, res.Metadata(nil), res.Best())
+}
+
+func (s *Server) stop(w http.ResponseWriter, r *http.Request) {
+	if j := s.job(w, r); j != nil {
+		j.stop()
+		s.dequeue(j)
+		writeJSON(w, http.StatusOK, j.status())
+	}
+}
+
+// Take a cancelled job out of the queue if it is still waiting, so it frees its place for another job
+func (s *Server) dequeue(j *job) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if i := slices.Index(s.queue, j); i >= 0 {
+		s.queue = slices.Delete(s.queue, i, i+1)
+	}
+}
+
+// Get the job of the ID in the path of the request, or respond that it doesn't exist
+func (s *Server) job(w http.ResponseWriter, r *http.Request) *job {
+	id := r.PathValue("id")
+	s.mu.Lock()
+	j, ok := s.jobs[id]
+	s.mu.Unlock()
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job "+id+" not found"))
+		return nil
+	}
+	return j
+}
+
+// Represents an error in the responses of the server
+// Fields: every invalid field when the error is an invalid configuration
+type Error struct {
+	Error  string       `json:"error"`
+	Fields []FieldError `json:"fields,omitempty"`
+}
+
+// Represents an invalid field of a submitted configuration
+type FieldError struct {
+	Path   string `json:"path"`
+	Reason string `json:"reason"`
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	resp := Error{Error: err.Error()}
+	var v *config.ValidationError
+	if errors.As(err, &v) {
+		for _, fe := range v.Errors {
+			resp.Fields = append(resp.Fields, FieldError{Path: fe.Path, Reason: fe.Reason})
+		}
+	}
+	writeJSON(w, status, resp)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
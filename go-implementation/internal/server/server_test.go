@@ -0,0 +1,343 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/metrics"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+// Small configuration solved in a few milliseconds
+const testConfig = `{"num_runs": 2, "num_queens": 8, "population_size": 50, "max_generations": 50, "seed": 42}`
+
+// Configuration that runs until it is cancelled
+const slowConfig = `{"num_runs": 2, "num_queens": 200, "population_size": 20, "max_generations": 1000000, "seed": 42}`
+
+func newTestServer(t *testing.T, opts Options) *httptest.Server {
+	t.Helper()
+	s := New(opts)
+	ts := httptest.NewServer(s)
+	t.Cleanup(func() {
+		ts.Close()
+		s.Close()
+	})
+	return ts
+}
+
+// Send a request and decode the JSON response into v, returning the status code
+func do(t *testing.T, method, url, body string, v any) int {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if v != nil {
+		if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+			t.Fatalf("%v %v: decode response: %v", method, url, err)
+		}
+	}
+	return resp.StatusCode
+}
+
+// Poll a job until it is in a finished state
+func waitFinished(t *testing.T, url string) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		var s JobStatus
+		do(t, http.MethodGet, url, "", &s)
+		if s.State.Finished() {
+			return s
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %v hasn't finished in time", url)
+	return JobStatus{}
+}
+
+// Poll a job until every run has evaluated a generation
+func waitProgress(t *testing.T, url string) JobStatus {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		var s JobStatus
+		do(t, http.MethodGet, url, "", &s)
+		started := s.State == StateRunning
+		for _, r := range s.Runs {
+			started = started && r.Best != nil
+		}
+		if started {
+			return s
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("job %v hasn't made progress in time", url)
+	return JobStatus{}
+}
+
+func TestServer_submit(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions)
+
+	var submitted JobStatus
+	if code := do(t, http.MethodPost, ts.URL+"/jobs", testConfig, &submitted); code != http.StatusAccepted {
+		t.Fatalf("POST /jobs status = %v, want %v", code, http.StatusAccepted)
+	}
+	if submitted.ID != "1" || submitted.Config.NumQueens != 8 || submitted.Config.Crossover != "ox" || len(submitted.Runs) != 2 {
+		t.Errorf("POST /jobs = %+v, want job 1 with the submitted fields and the default values of the others", submitted)
+	}
+
+	status := waitFinished(t, ts.URL+"/jobs/1")
+	if status.State != StateDone || status.StartedAt == nil || status.FinishedAt == nil {
+		t.Errorf("GET /jobs/1 = %+v, want a done job", status)
+	}
+	for _, r := range status.Runs {
+		if r.Best == nil || r.StopReason == "" || r.Best.BestQueenPositions == nil {
+			t.Errorf("GET /jobs/1 run %v = %+v, want a stopped run with its best generation", r.RunID, r)
+		}
+	}
+
+	var results struct {
+		Metadata result.Metadata           `json:"metadata"`
+		Results  []result.GenerationResult `json:"results"`
+	}
+	if code := do(t, http.MethodGet, ts.URL+"/jobs/1/results", "", &results); code != http.StatusOK {
+		t.Fatalf("GET /jobs/1/results status = %v, want %v", code, http.StatusOK)
+	}
+	if results.Metadata.Config.Seed != 42 || len(results.Results) != 2 {
+		t.Errorf("GET /jobs/1/results = %+v, want the 2 runs of seed 42", results)
+	}
+	for i, r := range results.Results {
+		if best := status.Runs[i].Best; r.RunID != i+1 || r.Seed != 42 || r.BestFitness != best.BestFitness || !reflect.DeepEqual(r.BestQueenPositions, best.BestQueenPositions) {
+			t.Errorf("GET /jobs/1/results result %v = %+v, want %+v", i, r, *best)
+		}
+	}
+
+	var jobs []JobStatus
+	if code := do(t, http.MethodGet, ts.URL+"/jobs", "", &jobs); code != http.StatusOK || len(jobs) != 1 || jobs[0].ID != "1" {
+		t.Errorf("GET /jobs = %v %+v, want job 1", code, jobs)
+	}
+}
+
+func TestServer_submit_invalid(t *testing.T) {
+	ts := newTestServer(t, DefaultOptions)
+
+	tests := []struct {
+		name       string
+		body       string
+		wantFields []string
+	}{
+		{"Invalid JSON", `{"num_queens": `, nil},
+		{"Unknown field", `{"queens": 8}`, []string{"queens"}},
+		{"Invalid type", `{"num_queens": "eight"}`, []string{"num_queens"}},
+		{"Invalid values", `{"num_queens": 2, "num_runs": 0}`, []string{"num_runs", "num_queens"}},
+		{"Unknown operator", `{"crossover": "unknown"}`, nil},
+		{"Single individual", `{"population_size": 1, "num_runs": 1, "max_generations": 5, "num_queens": 8}`, []string{"population_size"}},
+		{"Above the limits", `{"num_runs": 100, "population_size": 5000, "num_queens": 8}`, []string{"num_runs", "population_size"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var e Error
+			if code := do(t, http.MethodPost, ts.URL+"/jobs", tt.body, &e); code != http.StatusBadRequest {
+				t.Errorf("POST /jobs status = %v, want %v", code, http.StatusBadRequest)
+			}
+			if e.Error == "" || len(e.Fields) != len(tt.wantFields) {
+				t.Fatalf("POST /jobs error = %+v, want invalid fields %v", e, tt.wantFields)
+			}
+			for i, f := range e.Fields {
+				if f.Path != tt.wantFields[i] {
+					t.Errorf("POST /jobs invalid field %v = %v, want %v", i, f.Path, tt.wantFields[i])
+				}
+			}
+		})
+	}
+}
+
+func TestServer_cancel(t *testing.T) {
+	ts := newTestServer(t, Options{Workers: 1, QueueSize: 1, Retain: 10})
+
+	do(t, http.MethodPost, ts.URL+"/jobs", slowConfig, nil)
+	waitProgress(t, ts.URL+"/jobs/1")
+
+	// The worker is busy, so the second job waits and the third doesn't fit in the queue
+	var queued JobStatus
+	do(t, http.MethodPost, ts.URL+"/jobs", testConfig, &queued)
+	if queued.State != StateQueued {
+		t.Errorf("POST /jobs state = %v, want %v", queued.State, StateQueued)
+	}
+	var e Error
+	if code := do(t, http.MethodPost, ts.URL+"/jobs", testConfig, &e); code != http.StatusServiceUnavailable {
+		t.Errorf("POST /jobs to a full queue status = %v, want %v", code, http.StatusServiceUnavailable)
+	}
+	if code := do(t, http.MethodGet, ts.URL+"/jobs/1/results", "", &e); code != http.StatusConflict {
+		t.Errorf("GET /jobs/1/results of a running job status = %v, want %v", code, http.StatusConflict)
+	}
+
+	// A queued job is cancelled at once and never runs
+	var cancelled JobStatus
+	if code := do(t, http.MethodPost, ts.URL+"/jobs/2/cancel", "", &cancelled); code != http.StatusOK || cancelled.State != StateCancelled || cancelled.StartedAt != nil {
+		t.Errorf("POST /jobs/2/cancel = %v %+v, want a cancelled job that never started", code, cancelled)
+	}
+	if code := do(t, http.MethodGet, ts.URL+"/jobs/2/results", "", &e); code != http.StatusConflict {
+		t.Errorf("GET /jobs/2/results of a job cancelled while queued status = %v, want %v", code, http.StatusConflict)
+	}
+
+	// The cancelled job leaves the queue, making room for another one
+	if code := do(t, http.MethodPost, ts.URL+"/jobs", testConfig, &queued); code != http.StatusAccepted || queued.ID != "3" {
+		t.Errorf("POST /jobs after cancelling a queued job = %v %+v, want job 3 queued", code, queued)
+	}
+
+	// A running job keeps the results found so far
+	do(t, http.MethodPost, ts.URL+"/jobs/1/cancel", "", nil)
+	status := waitFinished(t, ts.URL+"/jobs/1")
+	if status.State != StateCancelled {
+		t.Errorf("GET /jobs/1 state = %v, want %v", status.State, StateCancelled)
+	}
+	var results struct {
+		Results []result.GenerationResult `json:"results"`
+	}
+	if code := do(t, http.MethodGet, ts.URL+"/jobs/1/results", "", &results); code != http.StatusOK || len(results.Results) != 2 {
+		t.Fatalf("GET /jobs/1/results = %v %+v, want the 2 interrupted runs", code, results)
+	}
+	for _, r := range results.Results {
+		if r.StopReason != result.StopInterrupted {
+			t.Errorf("GET /jobs/1/results stop reason = %v, want %v", r.StopReason, result.StopInterrupted)
+		}
+	}
+
+	if code := do(t, http.MethodGet, ts.URL+"/jobs/4", "", &e); code != http.StatusNotFound {
+		t.Errorf("GET /jobs/4 status = %v, want %v", code, http.StatusNotFound)
+	}
+}
+
+func TestServer_failed(t *testing.T) {
+	operator.RegisterCrossoverer("test-failing", func(cfg operator.Config) operator.Crossoverer {
+		return operator.CrossovererFunc(func(rng *rand.Rand, parent1, parent2 *operator.Individual) (*operator.Individual, *operator.Individual, error) {
+			return nil, nil, errors.New("crossover failed")
+		})
+	})
+	ts := newTestServer(t, DefaultOptions)
+
+	// The server keeps running and the job keeps the generations evaluated before the error
+	do(t, http.MethodPost, ts.URL+"/jobs", `{"num_runs": 2, "num_queens": 8, "crossover": "test-failing", "crossover_rate": 1, "seed": 42}`, nil)
+	status := waitFinished(t, ts.URL+"/jobs/1")
+	if status.State != StateFailed || !strings.Contains(status.Error, "crossover failed") {
+		t.Errorf("GET /jobs/1 = %+v, want a job failed by the crossover", status)
+	}
+	var results struct {
+		Results []result.GenerationResult `json:"results"`
+	}
+	if code := do(t, http.MethodGet, ts.URL+"/jobs/1/results", "", &results); code != http.StatusOK || len(results.Results) != 2 {
+		t.Fatalf("GET /jobs/1/results = %v %+v, want the 2 failed runs", code, results)
+	}
+	for _, r := range results.Results {
+		if r.StopReason != result.StopError {
+			t.Errorf("GET /jobs/1/results stop reason = %v, want %v", r.StopReason, result.StopError)
+		}
+	}
+}
+
+func TestServer_logs(t *testing.T) {
+	var buf bytes.Buffer
+	opts := DefaultOptions
+	opts.Logger = slog.New(slog.NewJSONHandler(&buf, nil))
+	ts := newTestServer(t, opts)
+
+	// The job logs while holding its lock, so the logs are complete once it is seen finished
+	do(t, http.MethodPost, ts.URL+"/jobs", testConfig, nil)
+	waitFinished(t, ts.URL+"/jobs/1")
+
+	got := []string{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("decode log %q: %v", line, err)
+		}
+		if entry["job_id"] != "1" || entry["config_hash"] == nil {
+			t.Errorf("Server logged %v, want the job ID and the config hash", entry)
+		}
+		got = append(got, entry["msg"].(string))
+		if entry["msg"] == "job finished" && (entry["state"] != string(StateDone) || entry["solutions"] == nil) {
+			t.Errorf("Server logged %v, want a done job with its solutions", entry)
+		}
+	}
+	if want := "job queued, job started, job finished"; strings.Join(got, ", ") != want {
+		t.Errorf("Server logged %v, want %v", got, want)
+	}
+}
+
+func TestServer_panicked(t *testing.T) {
+	operator.RegisterCrossoverer("test-panicking", func(cfg operator.Config) operator.Crossoverer {
+		return operator.CrossovererFunc(func(rng *rand.Rand, parent1, parent2 *operator.Individual) (*operator.Individual, *operator.Individual, error) {
+			panic("crossover panicked")
+		})
+	})
+	ts := newTestServer(t, DefaultOptions)
+
+	// The job fails and the server keeps serving
+	do(t, http.MethodPost, ts.URL+"/jobs", `{"num_runs": 2, "num_queens": 8, "workers": 2, "crossover": "test-panicking", "crossover_rate": 1, "seed": 42}`, nil)
+	status := waitFinished(t, ts.URL+"/jobs/1")
+	if status.State != StateFailed || !strings.Contains(status.Error, "crossover panicked") {
+		t.Errorf("GET /jobs/1 = %+v, want a job failed by the panic of the crossover", status)
+	}
+	if code := do(t, http.MethodPost, ts.URL+"/jobs", testConfig, nil); code != http.StatusAccepted {
+		t.Errorf("POST /jobs after a panicked job status = %v, want %v", code, http.StatusAccepted)
+	}
+}
+
+func TestServer_retain(t *testing.T) {
+	ts := newTestServer(t, Options{Workers: 1, QueueSize: 10, Retain: 1})
+
+	for i := 0; i < 3; i++ {
+		var s JobStatus
+		do(t, http.MethodPost, ts.URL+"/jobs", testConfig, &s)
+		waitFinished(t, ts.URL+"/jobs/"+s.ID)
+	}
+
+	// Submitting the third job forgets the first one, as only one finished job is kept
+	var jobs []JobStatus
+	do(t, http.MethodGet, ts.URL+"/jobs", "", &jobs)
+	ids := []string{}
+	for _, j := range jobs {
+		ids = append(ids, j.ID)
+	}
+	if strings.Join(ids, ",") != "2,3" {
+		t.Errorf("GET /jobs = %v, want jobs 2,3", ids)
+	}
+}
+
+func TestServer_metrics(t *testing.T) {
+	m := metrics.New()
+	ts := newTestServer(t, Options{Workers: 1, QueueSize: 10, Retain: 10, Metrics: m})
+
+	do(t, http.MethodPost, ts.URL+"/jobs", testConfig, nil)
+	status := waitFinished(t, ts.URL+"/jobs/1")
+
+	var b strings.Builder
+	m.WriteText(&b)
+	for _, r := range status.Runs {
+		want := fmt.Sprintf("nqueens_generation{job_id=\"1\",run=\"%v\"} %v\n", r.RunID, r.Generation)
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("metrics = %v, want them to contain %q", b.String(), want)
+		}
+	}
+	if !strings.Contains(b.String(), "nqueens_run_duration_seconds_count 2\n") {
+		t.Errorf("metrics = %v, want the duration of the 2 runs", b.String())
+	}
+}
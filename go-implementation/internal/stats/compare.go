@@ -0,0 +1,103 @@
+package stats
+
+import (
+	"math/rand/v2"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Represents the comparison of a metric of the runs of two result sets
+// MeanCI and MedianCI are bootstrap confidence intervals
+type MetricComparison struct {
+	Metric      string
+	A           Summary
+	B           Summary
+	AMeanCI     Interval
+	BMeanCI     Interval
+	AMedianCI   Interval
+	BMedianCI   Interval
+	MannWhitney MannWhitneyResult
+}
+
+// Represents the comparison of the success rates of two result sets
+type SuccessComparison struct {
+	ASolutions int
+	ARuns      int
+	BSolutions int
+	BRuns      int
+	ARate      float64
+	BRate      float64
+	ACI        Interval
+	BCI        Interval
+	FisherP    float64
+}
+
+// Represents the statistical comparison of two result sets, e.g. the results files of two configurations
+type Comparison struct {
+	Level     float64
+	Resamples int
+	Success   SuccessComparison
+	Metrics   []MetricComparison
+}
+
+// Metrics of the runs that are compared, taken from the best generation of every run
+var metrics = []struct {
+	name  string
+	value func(result.GenerationResult) float64
+}{
+	{"generations", func(r result.GenerationResult) float64 { return float64(r.Generation) }},
+	{"best_fitness", func(r result.GenerationResult) float64 { return float64(r.BestFitness) }},
+	{"mean_fitness", func(r result.GenerationResult) float64 { return r.MeanFitness }},
+	{"diversity", func(r result.GenerationResult) float64 { return r.Diversity }},
+}
+
+// Compare the best generation of every run of two result sets at the given confidence level, e.g. 0.95
+// Bootstrap intervals use the given number of resamples drawn from rng
+func Compare(rng *rand.Rand, a, b []result.GenerationResult, level float64, resamples int) Comparison {
+	aSolutions, bSolutions := result.GetNumSolutions(a), result.GetNumSolutions(b)
+	c := Comparison{
+		Level:     level,
+		Resamples: resamples,
+		Success: SuccessComparison{
+			ASolutions: aSolutions,
+			ARuns:      len(a),
+			BSolutions: bSolutions,
+			BRuns:      len(b),
+			ARate:      rate(aSolutions, len(a)),
+			BRate:      rate(bSolutions, len(b)),
+			ACI:        Wilson(aSolutions, len(a), level),
+			BCI:        Wilson(bSolutions, len(b), level),
+			FisherP:    FisherExact(aSolutions, len(a)-aSolutions, bSolutions, len(b)-bSolutions),
+		},
+	}
+
+	for _, m := range metrics {
+		xs, ys := values(a, m.value), values(b, m.value)
+		c.Metrics = append(c.Metrics, MetricComparison{
+			Metric:      m.name,
+			A:           Summarize(xs),
+			B:           Summarize(ys),
+			AMeanCI:     Bootstrap(rng, xs, Mean, resamples, level),
+			BMeanCI:     Bootstrap(rng, ys, Mean, resamples, level),
+			AMedianCI:   Bootstrap(rng, xs, Median, resamples, level),
+			BMedianCI:   Bootstrap(rng, ys, Median, resamples, level),
+			MannWhitney: MannWhitneyU(xs, ys),
+		})
+	}
+	return c
+}
+
+func values(results []result.GenerationResult, value func(result.GenerationResult) float64) []float64 {
+	xs := make([]float64, len(results))
+	for i, r := range results {
+		xs[i] = value(r)
+	}
+	return xs
+}
+
+func rate(successes, trials int) float64 {
+	if trials == 0 {
+		return 0
+	}
+	return float64(successes) / float64(trials)
+}
@@ -0,0 +1,45 @@
+package stats
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+func TestCompare(t *testing.T) {
+	runs := func(generations []int, solved int) []result.GenerationResult {
+		results := make([]result.GenerationResult, len(generations))
+		for i, g := range generations {
+			results[i] = result.GenerationResult{RunID: i + 1, Generation: g, BestFitness: 27, IsSolution: i < solved}
+			if results[i].IsSolution {
+				results[i].BestFitness = 28
+			}
+		}
+		return results
+	}
+	a := runs([]int{10, 12, 15, 11, 9, 14, 13, 10, 12, 11}, 10)
+	b := runs([]int{40, 35, 100, 42, 38, 100, 45, 39, 100, 41}, 7)
+
+	c := Compare(rand.New(rand.NewPCG(1, 2)), a, b, 0.95, 1000)
+	if c.Success.ASolutions != 10 || c.Success.BSolutions != 7 || c.Success.ARate != 1 || c.Success.BRate != 0.7 {
+		t.Errorf("Compare().Success = %+v, want 10 of 10 and 7 of 10 solutions", c.Success)
+	}
+	if c.Success.FisherP != FisherExact(10, 0, 7, 3) {
+		t.Errorf("Compare().Success.FisherP = %v, want %v", c.Success.FisherP, FisherExact(10, 0, 7, 3))
+	}
+	if len(c.Metrics) != len(metrics) {
+		t.Fatalf("Compare() compared %v metrics, want %v", len(c.Metrics), len(metrics))
+	}
+
+	generations := c.Metrics[0]
+	if generations.Metric != "generations" || generations.A.Median != 11.5 || generations.B.Median != 41.5 {
+		t.Errorf("Compare() generations = %+v, want medians 11.5 and 41.5", generations)
+	}
+	if generations.MannWhitney.P >= 0.001 || generations.MannWhitney.Z >= 0 {
+		t.Errorf("Compare() generations Mann-Whitney = %+v, want the first sample significantly smaller", generations.MannWhitney)
+	}
+	if generations.AMedianCI.Lower > 11.5 || generations.AMedianCI.Upper < 11.5 {
+		t.Errorf("Compare() generations median interval = %+v, want it to contain 11.5", generations.AMedianCI)
+	}
+}
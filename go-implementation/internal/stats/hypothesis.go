@@ -0,0 +1,118 @@
+package stats
+
+import (
+	"math"
+	"slices"
+)
+
+// Represents the result of a Mann-Whitney U test
+// U: statistic of the first sample, the number of pairs in which its value is greater, ties counting as half
+// Z: normal approximation of U, positive when the first sample tends to be greater
+// P: two-sided p-value
+type MannWhitneyResult struct {
+	U float64
+	Z float64
+	P float64
+}
+
+// Test whether the values of a sample tend to be greater or smaller than the ones of another with the Mann-Whitney U test
+// It doesn't assume the values are normally distributed, so it suits the number of generations of runs
+// The p-value uses the normal approximation with tie and continuity corrections, which is accurate with about 10 or more values per sample.
+// P is NaN if any sample is empty, and 1 if every value is equal
+func MannWhitneyU(xs, ys []float64) MannWhitneyResult {
+	n1, n2 := float64(len(xs)), float64(len(ys))
+	if len(xs) == 0 || len(ys) == 0 {
+		return MannWhitneyResult{math.NaN(), math.NaN(), math.NaN()}
+	}
+
+	// Rank both samples together, tied values get the mean of their ranks
+	type value struct {
+		x     float64
+		first bool
+	}
+	values := make([]value, 0, len(xs)+len(ys))
+	for _, x := range xs {
+		values = append(values, value{x, true})
+	}
+	for _, y := range ys {
+		values = append(values, value{y, false})
+	}
+	slices.SortFunc(values, func(a, b value) int {
+		switch {
+		case a.x < b.x:
+			return -1
+		case a.x > b.x:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	rankSum := 0.0
+	tieCorrection := 0.0
+	for i := 0; i < len(values); {
+		j := i
+		for j < len(values) && values[j].x == values[i].x {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if values[k].first {
+				rankSum += rank
+			}
+		}
+		t := float64(j - i)
+		tieCorrection += t*t*t - t
+		i = j
+	}
+
+	u := rankSum - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	n := n1 + n2
+	variance := n1 * n2 / 12 * ((n + 1) - tieCorrection/(n*(n-1)))
+	if variance <= 0 {
+		return MannWhitneyResult{U: u, Z: 0, P: 1}
+	}
+
+	diff := u - mean
+	continuity := math.Min(0.5, math.Abs(diff))
+	z := math.Copysign(math.Abs(diff)-continuity, diff) / math.Sqrt(variance)
+	return MannWhitneyResult{U: u, Z: z, P: math.Erfc(math.Abs(z) / math.Sqrt2)}
+}
+
+// Get the two-sided p-value of Fisher's exact test on a 2x2 contingency table:
+//
+//	            success  failure
+//	first       a        b
+//	second      c        d
+//
+// It tests whether the success rates of two samples differ and is exact with any number of trials,
+// adding the probabilities of every table with the same margins that is as likely or less likely than the observed one
+func FisherExact(a, b, c, d int) float64 {
+	row1, col1, n := a+b, a+c, a+b+c+d
+	if n == 0 {
+		return 1
+	}
+
+	observed := hypergeometric(a, row1, col1, n)
+	p := 0.0
+	for x := max(0, row1+col1-n); x <= min(row1, col1); x++ {
+		// Allow for rounding errors when comparing probabilities of tables as likely as the observed one
+		if prob := hypergeometric(x, row1, col1, n); prob <= observed*(1+1e-7) {
+			p += prob
+		}
+	}
+	return math.Min(1, p)
+}
+
+// Get the probability of a table with x successes in the first row given the margins of the table
+func hypergeometric(x, row1, col1, n int) float64 {
+	return math.Exp(logChoose(col1, x) + logChoose(n-col1, row1-x) - logChoose(n, row1))
+}
+
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
@@ -0,0 +1,53 @@
+package stats
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMannWhitneyU(t *testing.T) {
+	tests := []struct {
+		name string
+		xs   []float64
+		ys   []float64
+		want MannWhitneyResult
+	}{
+		{"Smaller", []float64{1, 2, 3}, []float64{4, 5, 6}, MannWhitneyResult{U: 0, Z: -1.7457431, P: 0.0808556}},
+		{"Greater", []float64{4, 5, 6}, []float64{1, 2, 3}, MannWhitneyResult{U: 9, Z: 1.7457431, P: 0.0808556}},
+		{"Ties", []float64{1, 2, 2, 3, 5}, []float64{2, 3, 4, 4, 6, 7}, MannWhitneyResult{U: 6.5, Z: -1.4809276, P: 0.1386259}},
+		{"Equal", []float64{2, 2}, []float64{2, 2, 2}, MannWhitneyResult{U: 3, Z: 0, P: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := MannWhitneyU(tt.xs, tt.ys)
+			if !almostEqual(got.U, tt.want.U, 1e-9) || !almostEqual(got.Z, tt.want.Z, 1e-6) || !almostEqual(got.P, tt.want.P, 1e-6) {
+				t.Errorf("MannWhitneyU() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	if got := MannWhitneyU(nil, []float64{1}); !math.IsNaN(got.P) {
+		t.Errorf("MannWhitneyU() of an empty sample = %+v, want NaN", got)
+	}
+}
+
+func TestFisherExact(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d int
+		want       float64
+	}{
+		{"Tea tasting", 3, 1, 1, 3, 0.4857143},
+		{"Significant", 1, 9, 11, 3, 0.0027594},
+		{"Same rates", 5, 5, 5, 5, 1},
+		{"Every run solved", 10, 0, 4, 6, 0.0108359},
+		{"Empty", 0, 0, 0, 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FisherExact(tt.a, tt.b, tt.c, tt.d); !almostEqual(got, tt.want, 1e-6) {
+				t.Errorf("FisherExact() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
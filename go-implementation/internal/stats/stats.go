@@ -0,0 +1,137 @@
+package stats
+
+import (
+	"math"
+	"math/rand/v2"
+	"slices"
+)
+
+// Get the mean of a sample, NaN if it is empty
+func Mean(xs []float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	total := 0.0
+	for _, x := range xs {
+		total += x
+	}
+	return total / float64(len(xs))
+}
+
+// Get the sample standard deviation, using n-1 degrees of freedom, NaN if the sample has less than 2 values
+func StdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return math.NaN()
+	}
+	mean := Mean(xs)
+	total := 0.0
+	for _, x := range xs {
+		total += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(total / float64(len(xs)-1))
+}
+
+// Get the q-quantile of a sample, with q between 0 and 1, interpolating linearly between the closest values
+// as most statistics packages do by default. NaN if the sample is empty or q is outside of [0, 1]
+func Quantile(xs []float64, q float64) float64 {
+	if len(xs) == 0 {
+		return math.NaN()
+	}
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+	return quantileSorted(sorted, q)
+}
+
+func quantileSorted(sorted []float64, q float64) float64 {
+	if !(q >= 0 && q <= 1) {
+		return math.NaN()
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (pos-float64(lower))*(sorted[upper]-sorted[lower])
+}
+
+// Get the median of a sample, NaN if it is empty
+func Median(xs []float64) float64 {
+	return Quantile(xs, 0.5)
+}
+
+// Represents the descriptive statistics of a sample
+type Summary struct {
+	N      int
+	Mean   float64
+	StdDev float64
+	Min    float64
+	Q1     float64
+	Median float64
+	Q3     float64
+	Max    float64
+}
+
+// Describe a sample, every statistic is NaN if it is empty
+func Summarize(xs []float64) Summary {
+	if len(xs) == 0 {
+		nan := math.NaN()
+		return Summary{Mean: nan, StdDev: nan, Min: nan, Q1: nan, Median: nan, Q3: nan, Max: nan}
+	}
+
+	sorted := slices.Clone(xs)
+	slices.Sort(sorted)
+	return Summary{
+		N:      len(xs),
+		Mean:   Mean(xs),
+		StdDev: StdDev(xs),
+		Min:    sorted[0],
+		Q1:     quantileSorted(sorted, 0.25),
+		Median: quantileSorted(sorted, 0.5),
+		Q3:     quantileSorted(sorted, 0.75),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// Represents a confidence interval
+type Interval struct {
+	Lower float64
+	Upper float64
+}
+
+// Get the two-sided z value of a confidence level between 0 and 1, e.g. 1.96 for 0.95
+func zValue(level float64) float64 {
+	return math.Sqrt2 * math.Erfinv(level)
+}
+
+// Estimate a confidence interval of a statistic of a sample with the percentile bootstrap:
+// the statistic is computed on resamples drawn with replacement and the interval is made of the quantiles of the results
+func Bootstrap(rng *rand.Rand, xs []float64, statistic func([]float64) float64, resamples int, level float64) Interval {
+	if len(xs) == 0 || resamples < 1 {
+		return Interval{math.NaN(), math.NaN()}
+	}
+
+	estimates := make([]float64, resamples)
+	resample := make([]float64, len(xs))
+	for i := range estimates {
+		for j := range resample {
+			resample[j] = xs[rng.IntN(len(xs))]
+		}
+		estimates[i] = statistic(resample)
+	}
+	slices.Sort(estimates)
+	alpha := (1 - level) / 2
+	return Interval{quantileSorted(estimates, alpha), quantileSorted(estimates, 1-alpha)}
+}
+
+// Get the Wilson score interval of a success rate, which unlike the normal approximation stays within 0 and 1
+// and works with few trials or rates close to 0 or 1
+func Wilson(successes, trials int, level float64) Interval {
+	if trials == 0 {
+		return Interval{math.NaN(), math.NaN()}
+	}
+
+	z := zValue(level)
+	n := float64(trials)
+	p := float64(successes) / n
+	center := (p + z*z/(2*n)) / (1 + z*z/n)
+	margin := z / (1 + z*z/n) * math.Sqrt(p*(1-p)/n+z*z/(4*n*n))
+	return Interval{math.Max(0, center-margin), math.Min(1, center+margin)}
+}
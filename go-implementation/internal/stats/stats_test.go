@@ -0,0 +1,114 @@
+package stats
+
+import (
+	"math"
+	"math/rand/v2"
+	"testing"
+)
+
+func almostEqual(a, b, tolerance float64) bool {
+	return math.Abs(a-b) <= tolerance
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name string
+		xs   []float64
+		want Summary
+	}{
+		{"Even length", []float64{4, 1, 3, 2}, Summary{N: 4, Mean: 2.5, StdDev: 1.2909944, Min: 1, Q1: 1.75, Median: 2.5, Q3: 3.25, Max: 4}},
+		{"Odd length", []float64{9, 2, 4, 4, 4, 5, 5, 7, 2}, Summary{N: 9, Mean: 4.6666667, StdDev: 2.2360680, Min: 2, Q1: 4, Median: 4, Q3: 5, Max: 9}},
+		{"Single value", []float64{3}, Summary{N: 1, Mean: 3, StdDev: math.NaN(), Min: 3, Q1: 3, Median: 3, Q3: 3, Max: 3}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Summarize(tt.xs)
+			gotValues := []float64{got.Mean, got.StdDev, got.Min, got.Q1, got.Median, got.Q3, got.Max}
+			wantValues := []float64{tt.want.Mean, tt.want.StdDev, tt.want.Min, tt.want.Q1, tt.want.Median, tt.want.Q3, tt.want.Max}
+			for i := range gotValues {
+				if !almostEqual(gotValues[i], wantValues[i], 1e-6) && !(math.IsNaN(gotValues[i]) && math.IsNaN(wantValues[i])) {
+					t.Errorf("Summarize() = %+v, want %+v", got, tt.want)
+					break
+				}
+			}
+			if got.N != tt.want.N {
+				t.Errorf("Summarize().N = %v, want %v", got.N, tt.want.N)
+			}
+		})
+	}
+
+	if got := Summarize(nil); got.N != 0 || !math.IsNaN(got.Median) {
+		t.Errorf("Summarize(nil) = %+v, want no values and NaN statistics", got)
+	}
+}
+
+func TestQuantile(t *testing.T) {
+	xs := []float64{4, 1, 3, 2}
+	tests := []struct {
+		name string
+		xs   []float64
+		q    float64
+		want float64
+	}{
+		{"Minimum", xs, 0, 1},
+		{"Median", xs, 0.5, 2.5},
+		{"Interpolated", xs, 0.9, 3.7},
+		{"Maximum", xs, 1, 4},
+		{"Empty sample", nil, 0.5, math.NaN()},
+		{"Below 0", xs, -0.1, math.NaN()},
+		{"Above 1", xs, 1.5, math.NaN()},
+		{"NaN", xs, math.NaN(), math.NaN()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Quantile(tt.xs, tt.q)
+			if !almostEqual(got, tt.want, 1e-9) && !(math.IsNaN(got) && math.IsNaN(tt.want)) {
+				t.Errorf("Quantile() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBootstrap(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+
+	constant := []float64{5, 5, 5, 5}
+	if got := Bootstrap(rng, constant, Mean, 1000, 0.95); got != (Interval{5, 5}) {
+		t.Errorf("Bootstrap() of a constant sample = %v, want {5 5}", got)
+	}
+
+	xs := make([]float64, 200)
+	for i := range xs {
+		xs[i] = rng.NormFloat64()*2 + 10
+	}
+	got := Bootstrap(rng, xs, Mean, 2000, 0.95)
+	if got.Lower >= Mean(xs) || got.Upper <= Mean(xs) || got.Upper-got.Lower > 1.5 {
+		t.Errorf("Bootstrap() = %v, want a narrow interval around %v", got, Mean(xs))
+	}
+
+	// A higher level gives a wider interval
+	if wider := Bootstrap(rand.New(rand.NewPCG(1, 2)), xs, Mean, 2000, 0.99); wider.Upper-wider.Lower <= got.Upper-got.Lower {
+		t.Errorf("Bootstrap() at 0.99 = %v, want wider than %v", wider, got)
+	}
+}
+
+func TestWilson(t *testing.T) {
+	tests := []struct {
+		name      string
+		successes int
+		trials    int
+		want      Interval
+	}{
+		{"8 of 10", 8, 10, Interval{0.4901625, 0.9433178}},
+		{"None", 0, 10, Interval{0, 0.2775328}},
+		{"Every trial", 10, 10, Interval{0.7224672, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Wilson(tt.successes, tt.trials, 0.95)
+			if !almostEqual(got.Lower, tt.want.Lower, 1e-6) || !almostEqual(got.Upper, tt.want.Upper, 1e-6) {
+				t.Errorf("Wilson() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
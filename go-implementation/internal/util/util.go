@@ -1,15 +1,21 @@
 package util
 
-import "math/rand"
+import "math/rand/v2"
 
-func Sample[T any](s []T, n int) []T {
+// Create a deterministic random number generator for the given seed and stream
+// Every run of the genetic algorithm uses its own stream so runs sharing a seed don't share random numbers
+func NewRNG(seed, stream uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(seed, stream))
+}
+
+func Sample[T any](rng *rand.Rand, s []T, n int) []T {
 	length := len(s)
 	if n > length {
 		n = length
 	}
 
 	result := make([]T, n)
-	perm := rand.Perm(length)[:n]
+	perm := rng.Perm(length)[:n]
 
 	for i, j := range perm {
 		result[i] = s[j]
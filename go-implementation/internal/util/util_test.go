@@ -8,7 +8,7 @@ import (
 func TestSample(t *testing.T) {
 	numbers := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
 	n := 5
-	got := Sample(numbers, n)
+	got := Sample(NewRNG(1, 1), numbers, n)
 	// Check if the length of the result is the same as the input
 	if len(got) != n {
 		t.Errorf("Sample() = %v, want %v", len(got), n)
@@ -28,3 +28,21 @@ func TestSample(t *testing.T) {
 		}
 	}
 }
+
+func TestNewRNG(t *testing.T) {
+	// Same seed and stream must produce the same sequence
+	a := NewRNG(42, 1)
+	b := NewRNG(42, 1)
+	for i := 0; i < 100; i++ {
+		if x, y := a.Uint64(), b.Uint64(); x != y {
+			t.Fatalf("NewRNG() produced different sequences for the same seed: %v != %v", x, y)
+		}
+	}
+
+	// Different streams must produce different sequences
+	c := NewRNG(42, 1)
+	d := NewRNG(42, 2)
+	if slices.Equal(c.Perm(20), d.Perm(20)) {
+		t.Errorf("NewRNG() produced the same sequence for different streams")
+	}
+}
@@ -0,0 +1,78 @@
+// Package nqueens solves the N-Queens problem with a genetic algorithm
+// It is the stable entry point of the module: build a Solver from a configuration and options, then call Solve
+package nqueens
+
+import (
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/population"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Aliases so the configuration and results can be used outside of the module
+type Config = config.Config
+type Duration = config.Duration
+type SelectionMethodType = config.SelectionMethodType
+type TopologyType = config.TopologyType
+type LearningType = config.LearningType
+type LocalSearchTargetType = config.LocalSearchTargetType
+type StagnationActionType = config.StagnationActionType
+type ValidationError = config.ValidationError
+type FieldError = config.FieldError
+type GenerationResult = result.GenerationResult
+type RunResult = result.RunResult
+type StopReason = result.StopReason
+type Metadata = result.Metadata
+type Progress = population.Progress
+
+// Reasons why a run stops
+const (
+	StopSolution       = result.StopSolution
+	StopMaxGenerations = result.StopMaxGenerations
+	StopTimeout        = result.StopTimeout
+	StopInterrupted    = result.StopInterrupted
+	StopError          = result.StopError
+)
+
+// Values of the fields of the configuration
+const (
+	Tournament = config.Tournament
+	Roulette   = config.Roulette
+
+	RingTopology   = config.RingTopology
+	FullTopology   = config.FullTopology
+	RandomTopology = config.RandomTopology
+
+	Lamarckian = config.Lamarckian
+	Baldwinian = config.Baldwinian
+
+	Offspring = config.Offspring
+	Elites    = config.Elites
+
+	Reseed        = config.Reseed
+	Hypermutation = config.Hypermutation
+)
+
+// Names of the built-in crossover and mutation operators, more can be registered in the operator package
+const (
+	OrderCrossover             = config.OrderCrossover
+	PartiallyMappedCrossover   = config.PartiallyMappedCrossover
+	CycleCrossover             = config.CycleCrossover
+	EdgeRecombinationCrossover = config.EdgeRecombinationCrossover
+	PositionBasedCrossover     = config.PositionBasedCrossover
+	SwapMutation               = config.SwapMutation
+	InversionMutation          = config.InversionMutation
+	ScrambleMutation           = config.ScrambleMutation
+	InsertionMutation          = config.InsertionMutation
+	ConflictSwapMutation       = config.ConflictSwapMutation
+)
+
+// Get the default configuration, which solves 29 queens
+func DefaultConfig() Config {
+	return config.DefaultConfig
+}
+
+// Load a JSON, YAML or TOML configuration file, in the format given by its extension
+// Omitted fields keep their default values, unknown or invalid fields are reported at once in the error
+func LoadConfig(path string) (Config, error) {
+	return config.LoadFile(path)
+}
@@ -0,0 +1,80 @@
+package nqueens
+
+import (
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+)
+
+// Represents the results of a Solve call
+// Runs are in run ID order and only have their history when the configuration records it
+type Result struct {
+	Config     Config
+	Runs       []RunResult
+	StartedAt  time.Time
+	FinishedAt time.Time
+}
+
+// Get the best generation of every run
+func (r *Result) Best() []GenerationResult {
+	best := make([]GenerationResult, len(r.Runs))
+	for i, run := range r.Runs {
+		best[i] = run.Best
+	}
+	return best
+}
+
+// Get every generation of every run, empty unless the configuration records history
+func (r *Result) History() []GenerationResult {
+	history := []GenerationResult{}
+	for _, run := range r.Runs {
+		history = append(history, run.History...)
+	}
+	return history
+}
+
+// Get the time it took to solve
+func (r *Result) Elapsed() time.Duration {
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Describe how the results were produced, to save them along with them
+// The command is the command line that produced them, if any
+func (r *Result) Metadata(command []string) Metadata {
+	return result.NewMetadata(r.Config, r.StartedAt, r.FinishedAt, command)
+}
+
+// Get the number of runs that found a solution
+func (r *Result) NumSolutions() int {
+	return result.GetNumSolutions(r.Best())
+}
+
+// Get the number of runs that were interrupted before finishing
+func (r *Result) NumInterrupted() int {
+	return result.GetNumInterrupted(r.Best())
+}
+
+// Get the mean number of generations of the runs
+func (r *Result) MeanGenerations() float64 {
+	return result.GetMeanGenerations(r.Best())
+}
+
+// Get the best fitness of any run
+func (r *Result) BestFitness() int {
+	return result.GetBestFitness(r.Best())
+}
+
+// Get the best fitness of the worst run
+func (r *Result) WorstFitness() int {
+	return result.GetWorstFitness(r.Best())
+}
+
+// Get the mean of the best fitness of the runs
+func (r *Result) MeanBestFitness() float64 {
+	return result.GetMeanBestFitness(r.Best())
+}
+
+// Get the mean of the mean fitness of the best generation of the runs
+func (r *Result) MeanMeanFitness() float64 {
+	return result.GetMeanMeanFitness(r.Best())
+}
@@ -0,0 +1,281 @@
+package nqueens
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/population"
+	"github.com/dmarts05/genetic-n-queens/internal/result"
+	"github.com/dmarts05/genetic-n-queens/internal/util"
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+// Runs the genetic algorithm with a configuration
+// Every run of a Solve call evolves concurrently and the same seed always gives the same results
+type Solver struct {
+	cfg                config.Config
+	checkpointPath     string
+	checkpointInterval int
+	resume             *population.Checkpoint
+
+	hookMu            sync.Mutex
+	onGeneration      []func(Progress)
+	onRunFinished     []func(GenerationResult)
+	onCheckpointError []func(error)
+}
+
+// Changes how a Solver runs
+type Option func(*Solver)
+
+// Seed the runs, 0 picks a random seed which is kept in the configuration of the solver
+func WithSeed(seed uint64) Option {
+	return func(s *Solver) { s.cfg.Seed = seed }
+}
+
+// Set the number of runs
+func WithRuns(n int) Option {
+	return func(s *Solver) { s.cfg.NumRuns = n }
+}
+
+// Set the number of goroutines evaluating and breeding the population of every run, results don't depend on it
+func WithWorkers(n int) Option {
+	return func(s *Solver) { s.cfg.Workers = n }
+}
+
+// Stop every run after the given duration, 0 means no limit
+func WithMaxDuration(d time.Duration) Option {
+	return func(s *Solver) { s.cfg.MaxDuration = config.Duration(d) }
+}
+
+// Keep every generation of every run in the results, not only the best one
+func WithHistory(record bool) Option {
+	return func(s *Solver) { s.cfg.RecordHistory = record }
+}
+
+// Save the state of every run to a file every interval generations, so it can be resumed with Resume
+// Checkpoints aren't supported in island mode
+func WithCheckpoint(path string, interval int) Option {
+	return func(s *Solver) {
+		s.checkpointPath = path
+		s.checkpointInterval = interval
+	}
+}
+
+// Call fn with the progress of every run when it starts, after every generation and once more when the run stops
+func OnGeneration(fn func(Progress)) Option {
+	return func(s *Solver) { s.onGeneration = append(s.onGeneration, fn) }
+}
+
+// Call fn with the best generation of every run when it stops, along with why it stopped
+func OnRunFinished(fn func(GenerationResult)) Option {
+	return func(s *Solver) { s.onRunFinished = append(s.onRunFinished, fn) }
+}
+
+// Call fn with the error of every checkpoint that can't be saved, the runs keep evolving and the next checkpoint is tried anyway
+func OnCheckpointError(fn func(error)) Option {
+	return func(s *Solver) { s.onCheckpointError = append(s.onCheckpointError, fn) }
+}
+
+// Create a solver of the configuration changed by the options
+// The configuration and its operators are checked, so Solve can't fail because of them
+func New(cfg Config, opts ...Option) (*Solver, error) {
+	s := &Solver{cfg: cfg}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+
+	// Pick a random seed if none was provided so the runs can still be replayed later
+	if s.cfg.Seed == 0 {
+		s.cfg.Seed = rand.Uint64()
+	}
+	return s, nil
+}
+
+// Create a solver that continues the runs saved in a checkpoint file with the configuration of the checkpoint
+// The checkpoint keeps being updated, with its interval, unless another path is given with WithCheckpoint. Options can't change the configuration
+func Resume(path string, opts ...Option) (*Solver, error) {
+	checkpoint, err := population.LoadCheckpoint(path)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Solver{cfg: checkpoint.Config, checkpointPath: path, checkpointInterval: checkpoint.Interval, resume: &checkpoint}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.cfg != checkpoint.Config {
+		return nil, errors.New("resume: the configuration of a checkpoint can't be changed")
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Check the configuration, its operators and the checkpoint options
+func (s *Solver) check() error {
+	if err := s.cfg.Validate(); err != nil {
+		return err
+	}
+	if _, err := operator.FromConfig(s.cfg); err != nil {
+		return err
+	}
+
+	// Islands exchange individuals at any moment, so their state can't be saved consistently
+	if s.checkpointPath != "" && s.cfg.IslandMode {
+		return errors.New("checkpoints are not supported in island mode")
+	}
+	if s.checkpointPath != "" && s.checkpointInterval < 1 {
+		return fmt.Errorf("checkpoint interval must be at least 1, got %v", s.checkpointInterval)
+	}
+	return nil
+}
+
+// Get the configuration of the solver, with the seed it uses
+func (s *Solver) Config() Config {
+	return s.cfg
+}
+
+// Check whether the solver continues runs saved in a checkpoint
+func (s *Solver) Resuming() bool {
+	return s.resume != nil
+}
+
+// Get the path of the checkpoint the state of the runs is saved to, empty if there is none
+func (s *Solver) CheckpointPath() string {
+	return s.checkpointPath
+}
+
+// Run every run of the configuration until they stop and return their results
+// Cancelling the context interrupts every run: the results found so far are returned along with the error of the context
+// A run whose operators fail stops with StopError, the results are still returned along with the errors of the failed runs
+// Hooks are called one at a time from the goroutines of the runs, so they should return quickly
+func (s *Solver) Solve(ctx context.Context) (*Result, error) {
+	r := &Result{Config: s.cfg, StartedAt: time.Now()}
+
+	var runs []RunResult
+	if s.cfg.IslandMode {
+		runs = population.EvolveIslands(ctx, s.cfg, s.observe)
+	} else {
+		runs = s.runIndependently(ctx)
+	}
+	r.FinishedAt = time.Now()
+
+	errs := []error{}
+	for i := range runs {
+		s.setSeed(&runs[i])
+		errs = append(errs, runs[i].Err)
+	}
+	r.Runs = runs
+	return r, errors.Join(append(errs, ctx.Err())...)
+}
+
+// Run only the run with the given ID, from 1 to the number of runs, until it stops and return its result
+// It is the same run Solve runs with that ID, so the runs of a configuration can be spread over several goroutines
+// Islands evolve together and checkpoints save every run at once, so they can't be run one at a time
+// The result is returned along with the error of the run or the context, as Solve does
+func (s *Solver) SolveRun(ctx context.Context, runID int) (RunResult, error) {
+	if s.cfg.IslandMode || s.checkpointPath != "" {
+		return RunResult{}, errors.New("runs can't be solved one at a time in island mode or with checkpoints")
+	}
+	if runID < 1 || runID > s.cfg.NumRuns {
+		return RunResult{}, fmt.Errorf("run ID must be between 1 and %v, got %v", s.cfg.NumRuns, runID)
+	}
+
+	rng := util.NewRNG(s.cfg.Seed, uint64(runID))
+	pop := population.Generate(rng, s.cfg.NumQueens, s.cfg.PopulationSize)
+	r := population.EvolveRun(ctx, runID, rng, pop, s.cfg, nil, s.observe)
+	s.setSeed(&r)
+	return r, errors.Join(r.Err, ctx.Err())
+}
+
+// Set the seed of the solver on the generations of a run
+func (s *Solver) setSeed(r *RunResult) {
+	r.Best.Seed = s.cfg.Seed
+	for i := range r.History {
+		r.History[i].Seed = s.cfg.Seed
+	}
+}
+
+// Run every run in its own goroutine, continuing from their saved state when resuming a checkpoint
+func (s *Solver) runIndependently(ctx context.Context) []RunResult {
+	var cp *population.Checkpointer
+	switch {
+	case s.resume != nil:
+		cp = population.NewCheckpointerFrom(s.checkpointPath, *s.resume)
+	case s.checkpointPath != "":
+		cp = population.NewCheckpointer(s.checkpointPath, s.cfg, s.checkpointInterval)
+	}
+	if cp != nil {
+		cp.OnError(s.checkpointError)
+	}
+
+	var wg sync.WaitGroup
+	ch := make(chan result.RunResult, s.cfg.NumRuns)
+	for i := 0; i < s.cfg.NumRuns; i++ {
+		wg.Add(1)
+		if s.resume != nil {
+			go population.ResumeConcurrentWrapper(ctx, ch, &wg, s.resume.Runs[i], s.cfg, cp, s.observe)
+			continue
+		}
+
+		// Every run gets its own stream derived from the seed so results don't depend on goroutine scheduling
+		rng := util.NewRNG(s.cfg.Seed, uint64(i+1))
+		pop := population.Generate(rng, s.cfg.NumQueens, s.cfg.PopulationSize)
+		go population.EvolveConcurrentWrapper(ctx, i+1, ch, &wg, rng, pop, s.cfg, cp, s.observe)
+	}
+	wg.Wait()
+	close(ch)
+
+	// Results arrive in completion order, sort them so the output is the same for the same seed
+	runs := []RunResult{}
+	for r := range ch {
+		runs = append(runs, r)
+	}
+	slices.SortFunc(runs, func(a, b RunResult) int {
+		return a.Best.RunID - b.Best.RunID
+	})
+	return runs
+}
+
+// Call the hooks with the error of a checkpoint
+func (s *Solver) checkpointError(err error) {
+	s.hookMu.Lock()
+	defer s.hookMu.Unlock()
+	for _, fn := range s.onCheckpointError {
+		fn(err)
+	}
+}
+
+// Call the hooks with the progress of a run
+func (s *Solver) observe(p Progress) {
+	if len(s.onGeneration) == 0 && len(s.onRunFinished) == 0 {
+		return
+	}
+
+	s.hookMu.Lock()
+	defer s.hookMu.Unlock()
+	for _, fn := range s.onGeneration {
+		fn(p)
+	}
+	if !p.Done() {
+		return
+	}
+
+	best := p.Best
+	best.RunID = p.RunID
+	best.Seed = s.cfg.Seed
+	best.StopReason = p.StopReason
+	for _, fn := range s.onRunFinished {
+		fn(best)
+	}
+}
@@ -0,0 +1,275 @@
+package nqueens
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/pkg/operator"
+)
+
+func testConfig() Config {
+	cfg := DefaultConfig()
+	cfg.NumRuns = 3
+	cfg.NumQueens = 12
+	cfg.PopulationSize = 50
+	cfg.MaxGenerations = 50
+	cfg.Seed = 42
+	return cfg
+}
+
+func TestNew(t *testing.T) {
+	islands := testConfig()
+	islands.IslandMode = true
+	unknownCrossover := testConfig()
+	unknownCrossover.Crossover = "unknown"
+	fieldValues := testConfig()
+	fieldValues.SelectionMethod = Roulette
+	fieldValues.Crossover = PartiallyMappedCrossover
+	fieldValues.Mutation = InversionMutation
+	fieldValues.MigrationTopology = FullTopology
+	fieldValues.LocalSearchRate = 0.1
+	fieldValues.LocalSearchMode = Baldwinian
+	fieldValues.LocalSearchTarget = Elites
+	fieldValues.StagnationGenerations = 10
+	fieldValues.StagnationAction = Hypermutation
+
+	tests := []struct {
+		name    string
+		cfg     Config
+		opts    []Option
+		wantErr bool
+	}{
+		{"Valid", testConfig(), nil, false},
+		{"Exported field values", fieldValues, nil, false},
+		{"Options", testConfig(), []Option{WithRuns(2), WithWorkers(4), WithHistory(true), WithCheckpoint("checkpoint.json", 10)}, false},
+		{"Invalid runs", testConfig(), []Option{WithRuns(0)}, true},
+		{"Negative duration", testConfig(), []Option{WithMaxDuration(-1)}, true},
+		{"Unknown operator", unknownCrossover, nil, true},
+		{"Checkpoint in island mode", islands, []Option{WithCheckpoint("checkpoint.json", 10)}, true},
+		{"Checkpoint interval", testConfig(), []Option{WithCheckpoint("checkpoint.json", 0)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.cfg, tt.opts...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("New() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNew_options(t *testing.T) {
+	s, err := New(testConfig(), WithSeed(0), WithRuns(2), WithWorkers(4), WithHistory(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := s.Config()
+	if cfg.Seed == 0 {
+		t.Errorf("New() seed = 0, want a random seed")
+	}
+	if cfg.NumRuns != 2 || cfg.Workers != 4 || !cfg.RecordHistory {
+		t.Errorf("New() config = %+v, want the options applied", cfg)
+	}
+}
+
+func TestSolver_Solve(t *testing.T) {
+	finished := []GenerationResult{}
+	generations := map[int]int{}
+	s, err := New(testConfig(),
+		OnRunFinished(func(best GenerationResult) { finished = append(finished, best) }),
+		OnGeneration(func(p Progress) { generations[p.RunID]++ }),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := s.Solve(context.Background())
+	if err != nil {
+		t.Fatalf("Solve() error = %v", err)
+	}
+	if len(r.Runs) != 3 {
+		t.Fatalf("Solve() returned %v runs, want 3", len(r.Runs))
+	}
+	for i, run := range r.Runs {
+		if run.Best.RunID != i+1 || run.Best.Seed != 42 || run.Best.StopReason == "" {
+			t.Errorf("Solve() run %v = %+v, want run ID %v with seed 42 and a stop reason", i, run.Best, i+1)
+		}
+		if generations[run.Best.RunID] < run.Best.Generation {
+			t.Errorf("OnGeneration() called %v times for run %v, want at least %v", generations[run.Best.RunID], run.Best.RunID, run.Best.Generation)
+		}
+	}
+
+	if len(finished) != len(r.Runs) {
+		t.Fatalf("OnRunFinished() called %v times, want %v", len(finished), len(r.Runs))
+	}
+	for _, best := range finished {
+		if want := r.Runs[best.RunID-1].Best; !reflect.DeepEqual(best, want) {
+			t.Errorf("OnRunFinished() = %+v, want %+v", best, want)
+		}
+	}
+
+	// The same seed always gives the same results
+	again, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := again.Solve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(r.Runs, r2.Runs) {
+		t.Errorf("Solve() with the same seed = %v, want %v", r2.Best(), r.Best())
+	}
+}
+
+func TestSolver_SolveRun(t *testing.T) {
+	s, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := s.Solve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every run is the same run Solve runs with its ID
+	for _, w := range want.Runs {
+		got, err := s.SolveRun(context.Background(), w.Best.RunID)
+		if err != nil {
+			t.Fatalf("SolveRun() error = %v", err)
+		}
+		if !reflect.DeepEqual(got, w) {
+			t.Errorf("SolveRun() = %+v, want %+v", got.Best, w.Best)
+		}
+	}
+
+	islands := testConfig()
+	islands.IslandMode = true
+	tests := []struct {
+		name  string
+		cfg   Config
+		opts  []Option
+		runID int
+	}{
+		{"Run ID 0", testConfig(), nil, 0},
+		{"Run ID above the number of runs", testConfig(), nil, 4},
+		{"Island mode", islands, nil, 1},
+		{"Checkpoint", testConfig(), []Option{WithCheckpoint(filepath.Join(t.TempDir(), "checkpoint.json"), 10)}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := New(tt.cfg, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := s.SolveRun(context.Background(), tt.runID); err == nil {
+				t.Errorf("SolveRun() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestSolver_Solve_cancelled(t *testing.T) {
+	s, err := New(testConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	r, err := s.Solve(ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Solve() error = %v, want %v", err, context.Canceled)
+	}
+	if r == nil || len(r.Runs) != 3 || r.NumInterrupted() != 3 {
+		t.Errorf("Solve() = %+v, want 3 interrupted runs", r)
+	}
+}
+
+func TestSolver_Solve_failedOperator(t *testing.T) {
+	errCrossover := errors.New("crossover failed")
+	operator.RegisterCrossoverer("test-failing", func(cfg operator.Config) operator.Crossoverer {
+		return operator.CrossovererFunc(func(rng *rand.Rand, parent1, parent2 *operator.Individual) (*operator.Individual, *operator.Individual, error) {
+			return nil, nil, errCrossover
+		})
+	})
+
+	cfg := testConfig()
+	cfg.Crossover = "test-failing"
+	cfg.CrossOverRate = 1
+	s, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The runs stop after evaluating their first generation instead of exiting
+	r, err := s.Solve(context.Background())
+	if !errors.Is(err, errCrossover) {
+		t.Errorf("Solve() error = %v, want %v", err, errCrossover)
+	}
+	if r == nil || len(r.Runs) != 3 {
+		t.Fatalf("Solve() = %+v, want 3 runs", r)
+	}
+	for _, run := range r.Runs {
+		if run.Best.StopReason != StopError || run.Best.Generation != 1 || !errors.Is(run.Err, errCrossover) {
+			t.Errorf("Solve() run %v = %+v, %v, want a run stopped by the crossover after its first generation", run.Best.RunID, run.Best, run.Err)
+		}
+	}
+}
+
+func TestResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+	s, err := New(testConfig(), WithCheckpoint(path, 10))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := s.Solve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Options can't change the configuration of the checkpoint
+	if _, err := Resume(path, WithSeed(1)); err == nil {
+		t.Errorf("Resume() with a different seed error = nil, want an error")
+	}
+
+	resumed, err := Resume(path)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if !resumed.Resuming() || resumed.CheckpointPath() != path || resumed.Config() != s.Config() {
+		t.Errorf("Resume() = %+v, want a solver resuming %v", resumed, path)
+	}
+	got, err := resumed.Solve(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got.Best(), want.Best()) {
+		t.Errorf("Solve() of finished runs = %v, want %v", got.Best(), want.Best())
+	}
+}
+
+func TestSolver_Solve_checkpointError(t *testing.T) {
+	var errs []error
+	path := filepath.Join(t.TempDir(), "missing", "checkpoint.json")
+	s, err := New(testConfig(), WithCheckpoint(path, 10), OnCheckpointError(func(err error) {
+		errs = append(errs, err)
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Runs aren't stopped by checkpoints that can't be saved
+	res, err := s.Solve(context.Background())
+	if err != nil || len(res.Runs) != testConfig().NumRuns {
+		t.Fatalf("Solve() = %v, %v, want every run", res, err)
+	}
+	if len(errs) == 0 {
+		t.Errorf("Solve() reported no checkpoint errors, want the failed saves")
+	}
+}
@@ -0,0 +1,50 @@
+package operator
+
+import (
+	"math/rand/v2"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/selection"
+)
+
+func init() {
+	RegisterSelector(string(config.Tournament), func(cfg Config) Selector {
+		return SelectorFunc(func(rng *rand.Rand, population []*Individual) []*Individual {
+			return selection.SelectByTournament(rng, population, cfg.TournamentSize)
+		})
+	})
+	RegisterSelector(string(config.Roulette), func(cfg Config) Selector {
+		return SelectorFunc(selection.SelectByRoulette)
+	})
+
+	RegisterCrossoverer(config.OrderCrossover, crossoverMethod((*Individual).Crossover))
+	RegisterCrossoverer(config.PartiallyMappedCrossover, crossoverMethod((*Individual).CrossoverPMX))
+	RegisterCrossoverer(config.CycleCrossover, crossoverMethod((*Individual).CrossoverCX))
+	RegisterCrossoverer(config.EdgeRecombinationCrossover, crossoverMethod((*Individual).CrossoverERX))
+	RegisterCrossoverer(config.PositionBasedCrossover, crossoverMethod((*Individual).CrossoverPositionBased))
+
+	RegisterMutator(config.SwapMutation, mutationMethod((*Individual).Mutate))
+	RegisterMutator(config.InversionMutation, mutationMethod((*Individual).MutateInversion))
+	RegisterMutator(config.ScrambleMutation, mutationMethod((*Individual).MutateScramble))
+	RegisterMutator(config.InsertionMutation, mutationMethod((*Individual).MutateInsertion))
+	RegisterMutator(config.ConflictSwapMutation, mutationMethod((*Individual).MutateConflictSwap))
+}
+
+// Build a crossover factory from a crossover method of the individuals
+func crossoverMethod(method func(parent1 *Individual, rng *rand.Rand, parent2 *Individual) (*Individual, *Individual, error)) CrossovererFactory {
+	return func(cfg Config) Crossoverer {
+		return CrossovererFunc(func(rng *rand.Rand, parent1, parent2 *Individual) (*Individual, *Individual, error) {
+			return method(parent1, rng, parent2)
+		})
+	}
+}
+
+// Build a mutation factory from a mutation method of the individuals using the gene mutation probability of the configuration
+func mutationMethod(method func(ind *Individual, rng *rand.Rand, individualProbability float64)) MutatorFactory {
+	return func(cfg Config) Mutator {
+		geneProbability := cfg.GeneMutationProbability()
+		return MutatorFunc(func(rng *rand.Rand, ind *Individual) {
+			method(ind, rng, geneProbability)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+// Package operator defines the selection, crossover and mutation operators of the genetic algorithm
+// and a registry to look them up by name, so new operators can be plugged in from outside of the module
+package operator
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+	"github.com/dmarts05/genetic-n-queens/internal/individual"
+)
+
+// Aliases so operators can be implemented outside of the module
+type Individual = individual.Individual
+type Config = config.Config
+
+// Selects the parents of the next generation from the population
+type Selector interface {
+	Select(rng *rand.Rand, population []*Individual) []*Individual
+}
+
+// Combines two parents into two children, the parents must not be modified
+type Crossoverer interface {
+	Crossover(rng *rand.Rand, parent1, parent2 *Individual) (*Individual, *Individual, error)
+}
+
+// Mutates an individual in place
+type Mutator interface {
+	Mutate(rng *rand.Rand, ind *Individual)
+}
+
+// Adapters to use ordinary functions as operators
+type SelectorFunc func(rng *rand.Rand, population []*Individual) []*Individual
+type CrossovererFunc func(rng *rand.Rand, parent1, parent2 *Individual) (*Individual, *Individual, error)
+type MutatorFunc func(rng *rand.Rand, ind *Individual)
+
+func (f SelectorFunc) Select(rng *rand.Rand, population []*Individual) []*Individual {
+	return f(rng, population)
+}
+
+func (f CrossovererFunc) Crossover(rng *rand.Rand, parent1, parent2 *Individual) (*Individual, *Individual, error) {
+	return f(rng, parent1, parent2)
+}
+
+func (f MutatorFunc) Mutate(rng *rand.Rand, ind *Individual) {
+	f(rng, ind)
+}
+
+// Factories build an operator from the configuration of a run, e.g. to read the tournament size
+type SelectorFactory func(cfg Config) Selector
+type CrossovererFactory func(cfg Config) Crossoverer
+type MutatorFactory func(cfg Config) Mutator
+
+var (
+	mu           sync.RWMutex
+	selectors    = map[string]SelectorFactory{}
+	crossoverers = map[string]CrossovererFactory{}
+	mutators     = map[string]MutatorFactory{}
+)
+
+// Register a factory under the given name, panicking if the name is already taken like database/sql does
+func register[F any](registry map[string]F, kind, name string, factory F) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("operator: %s %q registered twice", kind, name))
+	}
+	registry[name] = factory
+}
+
+// Look up the factory registered under the given name
+func lookup[F any](registry map[string]F, kind, name string) (F, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+	factory, ok := registry[name]
+	if !ok {
+		return factory, fmt.Errorf("unknown %s %q, available: %v", kind, name, names(registry))
+	}
+	return factory, nil
+}
+
+// Get the sorted names of a registry, the caller must hold the lock
+func names[F any](registry map[string]F) []string {
+	keys := make([]string, 0, len(registry))
+	for name := range registry {
+		keys = append(keys, name)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func RegisterSelector(name string, factory SelectorFactory) {
+	register(selectors, "selection method", name, factory)
+}
+
+func RegisterCrossoverer(name string, factory CrossovererFactory) {
+	register(crossoverers, "crossover operator", name, factory)
+}
+
+func RegisterMutator(name string, factory MutatorFactory) {
+	register(mutators, "mutation operator", name, factory)
+}
+
+func NewSelector(name string, cfg Config) (Selector, error) {
+	factory, err := lookup(selectors, "selection method", name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(cfg), nil
+}
+
+func NewCrossoverer(name string, cfg Config) (Crossoverer, error) {
+	factory, err := lookup(crossoverers, "crossover operator", name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(cfg), nil
+}
+
+func NewMutator(name string, cfg Config) (Mutator, error) {
+	factory, err := lookup(mutators, "mutation operator", name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(cfg), nil
+}
+
+// Get the names of the registered operators
+func Selectors() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names(selectors)
+}
+
+func Crossoverers() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names(crossoverers)
+}
+
+func Mutators() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return names(mutators)
+}
+
+// Represents the operators used by a run of the genetic algorithm
+type Set struct {
+	Selector    Selector
+	Crossoverer Crossoverer
+	Mutator     Mutator
+}
+
+// Build the operators named in the configuration
+func FromConfig(cfg Config) (Set, error) {
+	selector, err := NewSelector(string(cfg.SelectionMethod), cfg)
+	if err != nil {
+		return Set{}, err
+	}
+	crossoverer, err := NewCrossoverer(cfg.Crossover, cfg)
+	if err != nil {
+		return Set{}, err
+	}
+	mutator, err := NewMutator(cfg.Mutation, cfg)
+	if err != nil {
+		return Set{}, err
+	}
+	return Set{Selector: selector, Crossoverer: crossoverer, Mutator: mutator}, nil
+}
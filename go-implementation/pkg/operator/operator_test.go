@@ -0,0 +1,56 @@
+package operator
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/dmarts05/genetic-n-queens/internal/config"
+)
+
+func TestFromConfig(t *testing.T) {
+	ops, err := FromConfig(config.DefaultConfig)
+	if err != nil {
+		t.Fatalf("FromConfig() error = %v", err)
+	}
+	if ops.Selector == nil || ops.Crossoverer == nil || ops.Mutator == nil {
+		t.Errorf("FromConfig() = %v, want every operator set", ops)
+	}
+
+	cfg := config.DefaultConfig
+	cfg.Crossover = "unknown"
+	if _, err := FromConfig(cfg); err == nil {
+		t.Errorf("FromConfig() with unknown crossover operator error = nil, want error")
+	}
+}
+
+func TestRegisterMutator(t *testing.T) {
+	// Reverse the queens, which keeps the individual a valid permutation
+	RegisterMutator("test-reverse", func(cfg Config) Mutator {
+		return MutatorFunc(func(rng *rand.Rand, ind *Individual) {
+			slices.Reverse(ind.QueenPositions)
+			ind.Invalidate()
+		})
+	})
+	if !slices.Contains(Mutators(), "test-reverse") {
+		t.Errorf("Mutators() = %v, want it to contain test-reverse", Mutators())
+	}
+
+	mutator, err := NewMutator("test-reverse", config.DefaultConfig)
+	if err != nil {
+		t.Fatalf("NewMutator() error = %v", err)
+	}
+	ind := &Individual{QueenPositions: []int{0, 1, 2, 3}}
+	mutator.Mutate(rand.New(rand.NewPCG(1, 1)), ind)
+	if want := []int{3, 2, 1, 0}; !slices.Equal(ind.QueenPositions, want) {
+		t.Errorf("Mutator.Mutate() = %v, want %v", ind.QueenPositions, want)
+	}
+
+	// Registering the same name twice panics
+	defer func() {
+		if recover() == nil {
+			t.Errorf("RegisterMutator() with duplicate name did not panic")
+		}
+	}()
+	RegisterMutator("test-reverse", func(cfg Config) Mutator { return nil })
+}
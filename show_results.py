@@ -1,7 +1,7 @@
 import argparse
 import json
 import tkinter as tk
-from dataclasses import dataclass
+from dataclasses import dataclass, fields
 
 
 @dataclass
@@ -176,7 +176,12 @@ class SolutionCarousel(tk.Tk):
 def load_results_from_json(json_path: str) -> list[Solution]:
     with open(json_path, "r") as f:
         data = json.load(f)
-    return [Solution(**item) for item in data]
+    # Results may be preceded by the metadata of the run
+    if isinstance(data, dict):
+        data = data["results"]
+    # Ignore extra keys such as the run ID or the seed
+    keys = {field.name for field in fields(Solution)}
+    return [Solution(**{k: v for k, v in item.items() if k in keys}) for item in data]
 
 
 def main() -> None: